@@ -0,0 +1,43 @@
+// Command cleanup-uploads removes uploaded files that no record references
+// any more. Run with -dry-run to see what would be removed.
+package main
+
+import (
+	"context"
+	"ecommerce/backend/database"
+	"ecommerce/backend/services"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	dryRun := flag.Bool("dry-run", false, "report orphaned uploads without removing them")
+	grace := flag.Duration("grace", services.UploadGracePeriod(), "keep unreferenced uploads newer than this")
+	flag.Parse()
+
+	database.ConnectDB()
+	if err := services.ConfigureStorage(); err != nil {
+		log.Fatal("Failed to configure file storage: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	report, err := services.CleanupOrphanedUploads(ctx, *grace, *dryRun)
+	if err != nil {
+		log.Fatal("Cleanup failed: ", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
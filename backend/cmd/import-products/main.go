@@ -0,0 +1,84 @@
+// Command import-products creates or updates products by SKU from a CSV or
+// JSON file, printing a report of every row that failed.
+//
+// Usage: import-products [-batch-size n] [-start row] [-dry-run] file
+//
+// Without -batch-size the file is imported in one transaction and nothing is
+// written if any row fails. With it, batches commit separately and an
+// interrupted import resumes with -start set to the report's next_row.
+package main
+
+import (
+	"context"
+	"ecommerce/backend/database"
+	"ecommerce/backend/services"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	format := flag.String("format", "", "csv or json (default: from the file extension)")
+	batchSize := flag.Int("batch-size", 0, "commit every n rows, skipping failed rows (0 imports all or nothing)")
+	start := flag.Int("start", 1, "first row to import, to resume a batched import")
+	dryRun := flag.Bool("dry-run", false, "validate the file without saving anything")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatal("Usage: import-products [-format csv|json] [-batch-size n] [-start row] [-dry-run] file (- for stdin)")
+	}
+	path := flag.Arg(0)
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatal("Failed to open file: ", err)
+		}
+		defer file.Close()
+		input = file
+		if *format == "" {
+			*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		}
+	}
+
+	rows, err := services.ParseImport(*format, input)
+	if err != nil {
+		log.Fatal("Failed to read file: ", err)
+	}
+
+	database.ConnectDB()
+
+	// Interrupting a batched import stops after the current batch is rolled
+	// back, and the report says where to resume.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, importErr := services.ImportProducts(ctx, database.DB, rows, services.ImportOptions{
+		BatchSize: *batchSize,
+		Start:     *start,
+		DryRun:    *dryRun,
+	})
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	if importErr != nil {
+		log.Fatal("Import stopped: ", importErr)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
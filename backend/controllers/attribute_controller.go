@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"ecommerce/backend/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type attributePayload struct {
+	Name      string   `json:"name" binding:"required"`
+	Slug      string   `json:"slug"`
+	Type      string   `json:"type"`
+	Unit      string   `json:"unit"`
+	Options   []string `json:"options"`
+	Facetable *bool    `json:"facetable"`
+	SortOrder int      `json:"sort_order"`
+}
+
+// attributeOptions trims and de-duplicates the allowed values of a text
+// attribute.
+func attributeOptions(c *gin.Context, attributeType string, options []string) ([]string, bool) {
+	cleaned := []string{}
+	seen := map[string]bool{}
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[strings.ToLower(option)] {
+			continue
+		}
+		seen[strings.ToLower(option)] = true
+		cleaned = append(cleaned, option)
+	}
+	if len(cleaned) > 0 && attributeType != models.AttributeText {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only text attributes can have options"})
+		return nil, false
+	}
+	return cleaned, true
+}
+
+// GetCategoryAttributes lists the attributes products in the category can
+// have, including those inherited from parent categories.
+func GetCategoryAttributes(c *gin.Context) {
+	var category models.Category
+
+	if err := findCategory(c.Param("id"), &category); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	attributes, err := services.CategoryAttributes(database.DB, category.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load attributes"})
+		return
+	}
+	c.JSON(http.StatusOK, attributes)
+}
+
+func CreateCategoryAttribute(c *gin.Context) {
+	var category models.Category
+
+	if err := findCategory(c.Param("id"), &category); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	var body attributePayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attribute := models.Attribute{
+		CategoryID: category.ID,
+		Name:       strings.TrimSpace(body.Name),
+		Slug:       utils.Slugify(body.Slug),
+		Type:       body.Type,
+		Unit:       strings.TrimSpace(body.Unit),
+		Facetable:  body.Facetable == nil || *body.Facetable,
+		SortOrder:  body.SortOrder,
+	}
+	if attribute.Slug == "" {
+		attribute.Slug = utils.Slugify(body.Name)
+	}
+	if attribute.Slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attribute name must contain letters or digits"})
+		return
+	}
+	if attribute.Type == "" {
+		attribute.Type = models.AttributeText
+	}
+	if !services.ValidAttributeType(attribute.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of text, number, boolean"})
+		return
+	}
+	options, ok := attributeOptions(c, attribute.Type, body.Options)
+	if !ok {
+		return
+	}
+	attribute.Options = options
+
+	// A slug may only be defined once along any path of the category tree.
+	ancestors, err := services.AncestorCategoryIDs(database.DB, category.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load categories"})
+		return
+	}
+	descendants, err := services.DescendantCategoryIDs(database.DB, category.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load categories"})
+		return
+	}
+	var existing int64
+	database.DB.Model(&models.Attribute{}).
+		Where("slug = ? AND category_id IN ?", attribute.Slug, append(ancestors, descendants...)).
+		Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Attribute is already defined for this category, a parent or a subcategory"})
+		return
+	}
+
+	if err := services.CheckAttributeSlug(database.DB, attribute.Slug, attribute.Type); err != nil {
+		if err == services.ErrAttributeTypeConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Attribute slug is already used by another category with a different type"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create attribute"})
+		return
+	}
+
+	if err := database.DB.Create(&attribute).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create attribute"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attribute)
+}
+
+// UpdateAttribute changes how an attribute is shown and filtered. Its slug
+// and type are fixed once created, since product values depend on them.
+// Narrowing the options only affects values saved afterwards.
+func UpdateAttribute(c *gin.Context) {
+	id := c.Param("id")
+	var attribute models.Attribute
+
+	if err := database.DB.Where("id = ?", id).First(&attribute).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attribute not found"})
+		return
+	}
+
+	var body attributePayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if body.Type != "" && body.Type != attribute.Type {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The type of an attribute cannot be changed"})
+		return
+	}
+	options, ok := attributeOptions(c, attribute.Type, body.Options)
+	if !ok {
+		return
+	}
+
+	attribute.Name = strings.TrimSpace(body.Name)
+	attribute.Unit = strings.TrimSpace(body.Unit)
+	attribute.Options = options
+	attribute.SortOrder = body.SortOrder
+	if body.Facetable != nil {
+		attribute.Facetable = *body.Facetable
+	}
+
+	if err := database.DB.Save(&attribute).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attribute"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attribute)
+}
+
+// DeleteAttribute removes an attribute that no product in its categories
+// uses, trashed products included.
+func DeleteAttribute(c *gin.Context) {
+	id := c.Param("id")
+	var attribute models.Attribute
+
+	if err := database.DB.Where("id = ?", id).First(&attribute).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attribute not found"})
+		return
+	}
+
+	categories, err := services.DescendantCategoryIDs(database.DB, attribute.CategoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load categories"})
+		return
+	}
+
+	var products int64
+	database.DB.Unscoped().Model(&models.Product{}).
+		Where("category_id IN ? AND attributes->? IS NOT NULL", categories, attribute.Slug).
+		Count(&products)
+	if products > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Remove the attribute from its products (including trashed ones) first"})
+		return
+	}
+
+	database.DB.Delete(&attribute)
+	c.JSON(http.StatusOK, gin.H{"message": "Attribute deleted"})
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type cartItemPayload struct {
+	ProductID uint  `json:"product_id" binding:"required"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+type cartQuantityPayload struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+// cartError reports a product or variant that cannot go in the cart.
+func cartError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	case errors.Is(err, services.ErrVariantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+	case errors.Is(err, services.ErrVariantRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart"})
+	}
+}
+
+// presentItemProducts loads and prepares the products of list items. Items
+// whose product has been deleted or unpublished are left out.
+func presentItemProducts(c *gin.Context, productIDs []uint) (map[uint]*models.Product, bool) {
+	var products []models.Product
+	if len(productIDs) > 0 {
+		database.DB.Scopes(services.PublishedProducts).Where("id IN ?", productIDs).Find(&products)
+	}
+	if !presentProducts(c, products) {
+		return nil, false
+	}
+
+	byID := make(map[uint]*models.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+	return byID, true
+}
+
+func GetCart(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var items []models.CartItem
+	database.DB.Where("user_id = ?", userID).Order("id").Find(&items)
+
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	products, ok := presentItemProducts(c, ids)
+	if !ok {
+		return
+	}
+
+	available := []models.CartItem{}
+	for _, item := range items {
+		if product, ok := products[item.ProductID]; ok {
+			item.Product = product
+			available = append(available, item)
+		}
+	}
+	c.JSON(http.StatusOK, available)
+}
+
+func AddCartItem(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var body cartItemPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if body.Quantity == 0 {
+		body.Quantity = 1
+	}
+	if body.Quantity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be positive"})
+		return
+	}
+
+	item, err := services.AddToCart(database.DB, userID, body.ProductID, body.VariantID, body.Quantity)
+	if err != nil {
+		cartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func UpdateCartItem(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	var item models.CartItem
+
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart item not found"})
+		return
+	}
+
+	var body cartQuantityPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item.Quantity = body.Quantity
+	if err := database.DB.Model(&item).Update("quantity", item.Quantity).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func DeleteCartItem(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.CartItem{})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from cart"})
+}
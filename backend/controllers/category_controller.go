@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type categoryPayload struct {
+	Name      string `json:"name" binding:"required"`
+	Slug      string `json:"slug"`
+	ParentID  *uint  `json:"parent_id"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// categoryUpdatePayload changes only the fields that are sent; parent_id:
+// null moves the category to the top level.
+type categoryUpdatePayload struct {
+	Name      *string    `json:"name"`
+	Slug      string     `json:"slug"`
+	ParentID  nullableID `json:"parent_id"`
+	SortOrder *int       `json:"sort_order"`
+}
+
+// nullableID tells an explicit null apart from a field that was left out.
+type nullableID struct {
+	Set   bool
+	Value *uint
+}
+
+func (n *nullableID) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+	var id uint
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	n.Value = &id
+	return nil
+}
+
+// findCategory accepts either a numeric ID or a slug.
+func findCategory(idOrSlug string, category *models.Category) error {
+	if id, err := strconv.ParseUint(idOrSlug, 10, 64); err == nil {
+		return database.DB.First(category, id).Error
+	}
+	return database.DB.Where("slug = ?", idOrSlug).First(category).Error
+}
+
+func GetCategories(c *gin.Context) {
+	var categories []models.Category
+	database.DB.Order("sort_order, name").Find(&categories)
+	c.JSON(http.StatusOK, categories)
+}
+
+func GetCategoryTree(c *gin.Context) {
+	var categories []models.Category
+	database.DB.Find(&categories)
+	c.JSON(http.StatusOK, services.BuildCategoryTree(categories))
+}
+
+func GetCategory(c *gin.Context) {
+	var category models.Category
+
+	if err := findCategory(c.Param("id"), &category); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	database.DB.Where("parent_id = ?", category.ID).Order("sort_order, name").Find(&category.Children)
+	c.JSON(http.StatusOK, category)
+}
+
+// GetCategoryProducts lists products in a category and all of its
+// subcategories, with the same paging, sorting and filters as the catalog.
+func GetCategoryProducts(c *gin.Context) {
+	var category models.Category
+
+	if err := findCategory(c.Param("id"), &category); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	ids, err := services.DescendantCategoryIDs(database.DB, category.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load categories"})
+		return
+	}
+
+	q, err := parseProductQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filtered, err := parseProductFilters(c, database.DB.Model(&models.Product{}).Scopes(services.PublishedProducts).Where("products.category_id IN ?", ids))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count products"})
+		return
+	}
+
+	paged, err := q.apply(filtered.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		return
+	}
+
+	var products []models.Product
+	if err := paged.Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load products"})
+		return
+	}
+	products, next := q.nextCursor(products)
+
+	if !presentProducts(c, products) {
+		return
+	}
+
+	response := gin.H{"category": category, "data": products, "total": total, "limit": q.Limit, "sort": q.Sort, "next_cursor": next}
+	if q.cursor == nil {
+		response["page"] = q.Page
+	}
+	if !addFacets(c, response, filtered) {
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// validCategoryParent rejects a parent that does not exist or that would
+// create a cycle by sitting below the category itself.
+func validCategoryParent(c *gin.Context, categoryID uint, parentID *uint) bool {
+	if parentID == nil {
+		return true
+	}
+
+	var parent models.Category
+	if err := database.DB.First(&parent, *parentID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parent category not found"})
+		return false
+	}
+	if categoryID == 0 {
+		return true
+	}
+
+	descendants, err := services.DescendantCategoryIDs(database.DB, categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load categories"})
+		return false
+	}
+	for _, id := range descendants {
+		if id == *parentID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A category cannot be moved below itself"})
+			return false
+		}
+	}
+	return true
+}
+
+func CreateCategory(c *gin.Context) {
+	var body categoryPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := models.Category{
+		Name:      body.Name,
+		Slug:      services.CategorySlug(body.Slug),
+		ParentID:  body.ParentID,
+		SortOrder: body.SortOrder,
+	}
+	if category.Slug == "" {
+		category.Slug = services.CategorySlug(body.Name)
+	}
+	if category.Slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Category name must contain letters or digits"})
+		return
+	}
+	if !validCategoryParent(c, 0, body.ParentID) {
+		return
+	}
+
+	var existing int64
+	database.DB.Model(&models.Category{}).Where("slug = ?", category.Slug).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Category slug already in use"})
+		return
+	}
+
+	if err := database.DB.Create(&category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func UpdateCategory(c *gin.Context) {
+	var category models.Category
+
+	if err := findCategory(c.Param("id"), &category); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	var body categoryUpdatePayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if body.Name != nil {
+		if strings.TrimSpace(*body.Name) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+			return
+		}
+		category.Name = *body.Name
+	}
+	if body.ParentID.Set {
+		if !validCategoryParent(c, category.ID, body.ParentID.Value) {
+			return
+		}
+		category.ParentID = body.ParentID.Value
+	}
+	if body.SortOrder != nil {
+		category.SortOrder = *body.SortOrder
+	}
+	if slug := services.CategorySlug(body.Slug); slug != "" && slug != category.Slug {
+		var existing int64
+		database.DB.Model(&models.Category{}).Where("slug = ? AND id <> ?", slug, category.ID).Count(&existing)
+		if existing > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Category slug already in use"})
+			return
+		}
+		category.Slug = slug
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Children").Save(&category).Error; err != nil {
+			return err
+		}
+		// Keep the legacy category name on products in step with renames,
+		// including trashed ones so they are right when restored.
+		return tx.Unscoped().Model(&models.Product{}).
+			Where("category_id = ?", category.ID).
+			Update("category", category.Name).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func DeleteCategory(c *gin.Context) {
+	var category models.Category
+
+	if err := findCategory(c.Param("id"), &category); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	var children, products int64
+	database.DB.Model(&models.Category{}).Where("parent_id = ?", category.ID).Count(&children)
+	database.DB.Unscoped().Model(&models.Product{}).Where("category_id = ?", category.ID).Count(&products)
+	if children > 0 || products > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Move subcategories and products (including trashed ones) out of the category first"})
+		return
+	}
+
+	database.DB.Delete(&category)
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted"})
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+type exchangeRatePayload struct {
+	Rate float64 `json:"rate" binding:"required,gt=0"`
+}
+
+type productPricePayload struct {
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+// requestedCurrency reads the currency a client wants prices in, from the
+// ?currency= query or the X-Currency header. Empty means "as stored".
+func requestedCurrency(c *gin.Context) string {
+	if currency := c.Query("currency"); currency != "" {
+		return services.NormalizeCurrency(currency)
+	}
+	return services.NormalizeCurrency(c.GetHeader("X-Currency"))
+}
+
+// newPricer builds a pricer for the request and writes the error response
+// itself when the currency cannot be served.
+func newPricer(c *gin.Context, productIDs []uint) (*services.Pricer, bool) {
+	pricer, err := services.NewPricer(requestedCurrency(c), productIDs)
+	if errors.Is(err, services.ErrUnknownCurrency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported currency"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load exchange rates"})
+		return nil, false
+	}
+	return pricer, true
+}
+
+func localizeProducts(c *gin.Context, products []models.Product) bool {
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+
+	pricer, ok := newPricer(c, ids)
+	if !ok {
+		return false
+	}
+
+	for i := range products {
+		if err := pricer.Localize(&products[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No exchange rate for " + products[i].Currency})
+			return false
+		}
+	}
+	return true
+}
+
+func GetExchangeRates(c *gin.Context) {
+	var rates []models.ExchangeRate
+	database.DB.Order("currency").Find(&rates)
+	c.JSON(http.StatusOK, gin.H{"base": services.BaseCurrency(), "rates": rates})
+}
+
+func UpsertExchangeRate(c *gin.Context) {
+	currency := services.NormalizeCurrency(c.Param("currency"))
+	if !services.ValidCurrency(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid currency code"})
+		return
+	}
+	if currency == services.BaseCurrency() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The base currency always has a rate of 1"})
+		return
+	}
+
+	var body exchangeRatePayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate := models.ExchangeRate{Currency: currency, Rate: body.Rate}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(&rate).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save exchange rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+var errRateInUse = errors.New("exchange rate in use")
+
+// DeleteExchangeRate removes a rate unless products, including those in the
+// trash, are still priced in its currency.
+func DeleteExchangeRate(c *gin.Context) {
+	currency := services.NormalizeCurrency(c.Param("currency"))
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var rates []models.ExchangeRate
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("currency = ?", currency).Find(&rates).Error; err != nil {
+			return err
+		}
+
+		var inUse int64
+		if err := tx.Unscoped().Model(&models.Product{}).Where("currency = ?", currency).Count(&inUse).Error; err != nil {
+			return err
+		}
+		if inUse > 0 {
+			return errRateInUse
+		}
+		return tx.Where("currency = ?", currency).Delete(&models.ExchangeRate{}).Error
+	})
+	if errors.Is(err, errRateInUse) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Products are still priced in " + currency})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete exchange rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Exchange rate deleted"})
+}
+
+func GetProductPrices(c *gin.Context) {
+	var prices []models.ProductPrice
+	database.DB.Where("product_id IN (?)", productIDQuery(c.Param("id"))).Order("currency").Find(&prices)
+	c.JSON(http.StatusOK, prices)
+}
+
+func SetProductPrice(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product) {
+		return
+	}
+
+	currency := services.NormalizeCurrency(c.Param("currency"))
+	if !services.ValidCurrency(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid currency code"})
+		return
+	}
+
+	var body productPricePayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	price := models.ProductPrice{ProductID: product.ID, Currency: currency, Amount: body.Amount}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.CheckPriceOverridable(tx, product.ID); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"amount"}),
+		}).Create(&price).Error
+	})
+	if errors.Is(err, services.ErrPriceScheduled) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Products with price schedules cannot have per-currency prices"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save price"})
+		return
+	}
+
+	c.JSON(http.StatusOK, price)
+}
+
+func DeleteProductPrice(c *gin.Context) {
+	currency := services.NormalizeCurrency(c.Param("currency"))
+	database.DB.Where("product_id IN (?) AND currency = ?", productIDQuery(c.Param("id")), currency).Delete(&models.ProductPrice{})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price override deleted"})
+}
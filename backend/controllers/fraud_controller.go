@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+type fraudRulePayload struct {
+	Enabled       *bool    `json:"enabled"`
+	Threshold     *float64 `json:"threshold"`
+	WindowMinutes *int     `json:"window_minutes"`
+	Score         *int     `json:"score"`
+}
+
+type fraudReviewPayload struct {
+	Decision string `json:"decision" binding:"required,oneof=approved rejected"`
+	Note     string `json:"note"`
+}
+
+func GetFraudRules(c *gin.Context) {
+	var rules []models.FraudRule
+	database.DB.Order("code").Find(&rules)
+	c.JSON(http.StatusOK, rules)
+}
+
+func UpdateFraudRule(c *gin.Context) {
+	var rule models.FraudRule
+
+	if err := database.DB.Where("code = ?", c.Param("code")).First(&rule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fraud rule not found"})
+		return
+	}
+
+	var body fraudRulePayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if body.Enabled != nil {
+		rule.Enabled = *body.Enabled
+	}
+	if body.Threshold != nil {
+		rule.Threshold = *body.Threshold
+	}
+	if body.WindowMinutes != nil {
+		rule.WindowMinutes = *body.WindowMinutes
+	}
+	if body.Score != nil {
+		rule.Score = *body.Score
+	}
+
+	if err := database.DB.Save(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save fraud rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// GetHeldOrders is the review queue: orders on hold with their rule hits.
+func GetHeldOrders(c *gin.Context) {
+	var orders []models.Order
+	preloadOrderProduct(database.DB.Preload("User")).
+		Where("status = ?", models.OrderStatusOnHold).
+		Order("created_at").
+		Find(&orders)
+
+	ids := make([]uint, len(orders))
+	for i, order := range orders {
+		ids[i] = order.ID
+	}
+
+	var checks []models.FraudCheck
+	database.DB.Preload("Hits").Where("order_id IN ?", ids).Find(&checks)
+
+	byOrder := make(map[uint]models.FraudCheck, len(checks))
+	for _, check := range checks {
+		byOrder[check.OrderID] = check
+	}
+
+	queue := make([]gin.H, len(orders))
+	for i, order := range orders {
+		queue[i] = gin.H{"order": order, "fraud_check": byOrder[order.ID]}
+	}
+	c.JSON(http.StatusOK, queue)
+}
+
+func GetFraudCheck(c *gin.Context) {
+	var check models.FraudCheck
+
+	if err := database.DB.Preload("Hits").Where("order_id = ?", c.Param("id")).First(&check).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fraud check not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+// ReviewHeldOrder releases a held order back to pending or cancels it.
+func ReviewHeldOrder(c *gin.Context) {
+	id := c.Param("id")
+	var order models.Order
+
+	if err := database.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+	if order.Status != models.OrderStatusOnHold {
+		c.JSON(http.StatusConflict, gin.H{"error": "Order is not on hold"})
+		return
+	}
+
+	var body fraudReviewPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reviewer, _ := middleware.GetUserID(c)
+	now := time.Now()
+
+	status := models.OrderStatusPending
+	if body.Decision == models.FraudDecisionRejected {
+		status = models.OrderStatusCancelled
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.SetOrderStatus(tx, &order, status); err != nil {
+			return err
+		}
+		return tx.Model(&models.FraudCheck{}).
+			Where("order_id = ?", order.ID).
+			Updates(map[string]interface{}{
+				"decision":    body.Decision,
+				"note":        body.Note,
+				"reviewed_by": reviewer,
+				"reviewed_at": now,
+			}).Error
+	})
+	if errors.Is(err, services.ErrOrderStatusChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Order was already reviewed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
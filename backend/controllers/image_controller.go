@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type productImagePayload struct {
+	AltText   *string `json:"alt_text"`
+	IsPrimary bool    `json:"is_primary"`
+}
+
+type imageOrderPayload struct {
+	ImageIDs []uint `json:"image_ids" binding:"required,min=1"`
+}
+
+func preloadImages(db *gorm.DB) *gorm.DB {
+	return db.Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order, id") })
+}
+
+func productImages(c *gin.Context, productID uint) []models.ProductImage {
+	var images []models.ProductImage
+	database.DB.Where("product_id = ?", productID).Order("sort_order, id").Find(&images)
+	return attachGalleryURLs(c, images)
+}
+
+func GetProductImages(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product, services.PublishedProducts) {
+		return
+	}
+
+	c.JSON(http.StatusOK, productImages(c, product.ID))
+}
+
+// UploadProductImages appends one or more files sent as "images" (or a single
+// "image") to the end of the gallery. Set primary=true to make the first
+// uploaded image the primary one.
+func UploadProductImages(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product) {
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse form data"})
+		return
+	}
+	files := append(form.File["images"], form.File["image"]...)
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No images uploaded"})
+		return
+	}
+
+	altText := c.PostForm("alt_text")
+	if altText == "" {
+		altText = product.Title
+	}
+
+	var nextOrder int
+	database.DB.Model(&models.ProductImage{}).
+		Where("product_id = ?", product.ID).
+		Select("COALESCE(MAX(sort_order) + 1, 0)").
+		Scan(&nextOrder)
+
+	var images []models.ProductImage
+	for i, file := range files {
+		path, err := saveUpload(c, file)
+		if err != nil {
+			uploadError(c, err)
+			return
+		}
+		images = append(images, models.ProductImage{
+			ProductID: product.ID,
+			Path:      path,
+			AltText:   altText,
+			SortOrder: nextOrder + i,
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&images).Error; err != nil {
+			return err
+		}
+		if c.PostForm("primary") == "true" {
+			err := tx.Model(&models.ProductImage{}).
+				Where("product_id = ?", product.ID).
+				Update("is_primary", gorm.Expr("id = ?", images[0].ID)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return services.SyncPrimaryImage(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save images"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, productImages(c, product.ID))
+}
+
+func UpdateProductImage(c *gin.Context) {
+	var image models.ProductImage
+
+	err := database.DB.Where("id = ? AND product_id IN (?)", c.Param("imageId"), productIDQuery(c.Param("id"))).First(&image).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	var body productImagePayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if body.AltText != nil {
+			if err := tx.Model(&image).Update("alt_text", *body.AltText).Error; err != nil {
+				return err
+			}
+		}
+		if body.IsPrimary {
+			err := tx.Model(&models.ProductImage{}).
+				Where("product_id = ?", image.ProductID).
+				Update("is_primary", gorm.Expr("id = ?", image.ID)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return services.SyncPrimaryImage(tx, image.ProductID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
+		return
+	}
+
+	c.JSON(http.StatusOK, productImages(c, image.ProductID))
+}
+
+// ReorderProductImages sets the gallery order to the given list of image IDs,
+// which must name every image of the product exactly once.
+func ReorderProductImages(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product) {
+		return
+	}
+
+	var body imageOrderPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	current := productImages(c, product.ID)
+	known := map[uint]bool{}
+	for _, image := range current {
+		known[image.ID] = true
+	}
+	if len(body.ImageIDs) != len(current) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image_ids must list every image of the product"})
+		return
+	}
+	for _, imageID := range body.ImageIDs {
+		if !known[imageID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "image_ids must list every image of the product"})
+			return
+		}
+		delete(known, imageID)
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for position, imageID := range body.ImageIDs {
+			if err := tx.Model(&models.ProductImage{}).Where("id = ?", imageID).Update("sort_order", position).Error; err != nil {
+				return err
+			}
+		}
+		return services.SyncPrimaryImage(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder images"})
+		return
+	}
+
+	c.JSON(http.StatusOK, productImages(c, product.ID))
+}
+
+func DeleteProductImage(c *gin.Context) {
+	var image models.ProductImage
+
+	err := database.DB.Where("id = ? AND product_id IN (?)", c.Param("imageId"), productIDQuery(c.Param("id"))).First(&image).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	// Deleting the primary image promotes the next one in sort order.
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&image).Error; err != nil {
+			return err
+		}
+		return services.SyncPrimaryImage(tx, image.ProductID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete image"})
+		return
+	}
+
+	c.JSON(http.StatusOK, productImages(c, image.ProductID))
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const maxImportSize = 50 << 20
+
+// importFormat picks the format from ?format, then the file extension, then
+// the content type.
+func importFormat(c *gin.Context, filename, contentType string) string {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return services.ImportCSV
+	case ".json":
+		return services.ImportJSON
+	}
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return services.ImportCSV
+	case strings.Contains(contentType, "json"):
+		return services.ImportJSON
+	}
+	return ""
+}
+
+// importSource returns the uploaded file, sent either as the "file" field of
+// a multipart form or as the raw request body.
+func importSource(c *gin.Context) (io.ReadCloser, string, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	contentType := c.GetHeader("Content-Type")
+
+	if !strings.Contains(contentType, "multipart/form-data") {
+		return c.Request.Body, importFormat(c, "", contentType), true
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return nil, "", false
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return nil, "", false
+	}
+	return file, importFormat(c, header.Filename, header.Header.Get("Content-Type")), true
+}
+
+// ImportProducts creates or updates products by SKU from a CSV or JSON file.
+// By default the whole file is applied in one transaction and nothing is
+// written if any row fails. With mode=batch, every batch_size rows commit on
+// their own and failed rows are skipped; an interrupted import can be resumed
+// with start set to the report's next_row.
+func ImportProducts(c *gin.Context) {
+	opts := services.ImportOptions{DryRun: c.Query("dry_run") == "true", ChangedBy: adminID(c)}
+
+	switch c.DefaultQuery("mode", "atomic") {
+	case "atomic":
+	case "batch":
+		opts.BatchSize = services.DefaultImportBatchSize
+		if v := c.Query("batch_size"); v != "" {
+			size, err := strconv.Atoi(v)
+			if err != nil || size < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "batch_size must be a positive integer"})
+				return
+			}
+			opts.BatchSize = size
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be atomic or batch"})
+		return
+	}
+	if v := c.Query("start"); v != "" {
+		start, err := strconv.Atoi(v)
+		if err != nil || start < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start must be a positive integer"})
+			return
+		}
+		opts.Start = start
+	}
+
+	file, format, ok := importSource(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	rows, err := services.ParseImport(format, file)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := services.ImportProducts(c.Request.Context(), database.DB, rows, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Import stopped: " + err.Error(), "report": report})
+		return
+	}
+
+	status := http.StatusOK
+	if report.Mode == "atomic" && report.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, report)
+}
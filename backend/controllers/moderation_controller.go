@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+type moderationPayload struct {
+	Decision string `json:"decision" binding:"required,oneof=approved rejected"`
+	Note     string `json:"note"`
+}
+
+type bannedWordPayload struct {
+	Word string `json:"word" binding:"required,max=100"`
+}
+
+// parseModerationQueue reads the ?status= (pending by default) and paging of
+// a moderation queue.
+func parseModerationQueue(c *gin.Context) (string, int, int, bool) {
+	status := c.DefaultQuery("status", models.ModerationPending)
+	switch status {
+	case models.ModerationPending, models.ModerationApproved, models.ModerationRejected:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of pending, approved, rejected"})
+		return "", 0, 0, false
+	}
+	page, limit, err := parsePage(c, defaultReviewLimit, maxReviewLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", 0, 0, false
+	}
+	return status, page, limit, true
+}
+
+// GetReviewQueue lists reviews by status, pending by default, oldest first
+// so the queue is worked in order.
+func GetReviewQueue(c *gin.Context) {
+	status, page, limit, ok := parseModerationQueue(c)
+	if !ok {
+		return
+	}
+
+	var total int64
+	database.DB.Model(&models.Review{}).Where("status = ?", status).Count(&total)
+
+	var reviews []models.Review
+	reviewsWithAuthor(database.DB).
+		Where("reviews.status = ?", status).
+		Order("reviews.id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&reviews)
+
+	c.JSON(http.StatusOK, gin.H{"data": reviews, "total": total, "page": page, "limit": limit, "status": status})
+}
+
+func ModerateReview(c *gin.Context) {
+	id := c.Param("id")
+	var review models.Review
+
+	if err := reviewsWithAuthor(database.DB).Where("reviews.id = ?", id).First(&review).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+
+	var body moderationPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if review.Status == body.Decision {
+		c.JSON(http.StatusConflict, gin.H{"error": "Review is already " + body.Decision})
+		return
+	}
+
+	moderator, _ := middleware.GetUserID(c)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return services.ModerateReview(tx, &review, body.Decision, strings.TrimSpace(body.Note), moderator)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save decision"})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+func GetBannedWords(c *gin.Context) {
+	var words []models.BannedWord
+	database.DB.Order("word").Find(&words)
+	c.JSON(http.StatusOK, words)
+}
+
+func CreateBannedWord(c *gin.Context) {
+	var body bannedWordPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	word := models.BannedWord{Word: strings.ToLower(strings.TrimSpace(body.Word))}
+	if word.Word == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Word is required"})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.BannedWord{}).Where("word = ?", word.Word).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Word is already banned"})
+		return
+	}
+
+	if err := database.DB.Create(&word).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save word"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, word)
+}
+
+func DeleteBannedWord(c *gin.Context) {
+	id := c.Param("id")
+	database.DB.Where("id = ?", id).Delete(&models.BannedWord{})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Word removed"})
+}
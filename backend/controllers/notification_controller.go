@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
+	"ecommerce/backend/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// GetNotifications lists the current user's notifications, newest first.
+// Pass ?unread=true to skip the ones already read.
+func GetNotifications(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	query := database.DB.Where("user_id = ?", userID).Order("id DESC").Limit(100)
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	query.Find(&notifications)
+	c.JSON(http.StatusOK, notifications)
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	var notification models.Notification
+
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&notification).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		database.DB.Model(&notification).Update("read_at", now)
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
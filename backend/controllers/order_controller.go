@@ -2,14 +2,85 @@ package controllers
 
 import (
 	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
 	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"net/http"
 )
 
+// orderPayload holds the fields a customer chooses when placing an order;
+// prices, status and the user come from the server.
+type orderPayload struct {
+	ProductID       uint   `json:"product_id" binding:"required"`
+	VariantID       *uint  `json:"variant_id"`
+	Quantity        int    `json:"quantity"`
+	ShippingAddress string `json:"shipping_address"`
+	ShippingCountry string `json:"shipping_country" binding:"omitempty,len=2"`
+	BillingAddress  string `json:"billing_address"`
+	BillingCountry  string `json:"billing_country" binding:"omitempty,len=2"`
+}
+
+type orderAddressPayload struct {
+	ShippingAddress *string `json:"shipping_address"`
+	BillingAddress  *string `json:"billing_address"`
+}
+
+type orderStatusPayload struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// localizeOrders converts the captured order amounts and the embedded
+// product into the currency the client asked for.
+func localizeOrders(c *gin.Context, orders []models.Order) bool {
+	ids := make([]uint, len(orders))
+	for i, order := range orders {
+		ids[i] = order.ProductID
+	}
+
+	pricer, ok := newPricer(c, ids)
+	if !ok {
+		return false
+	}
+
+	for i := range orders {
+		order := &orders[i]
+		if err := pricer.Localize(&order.Product); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No exchange rate for " + order.Product.Currency})
+			return false
+		}
+		if order.Currency == "" {
+			continue
+		}
+
+		currency := order.Currency
+		unitPrice, _, err := pricer.Convert(order.UnitPrice, currency)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No exchange rate for " + currency})
+			return false
+		}
+		total, converted, _ := pricer.Convert(order.Total, currency)
+		order.UnitPrice, order.Total, order.Currency = unitPrice, total, converted
+	}
+	return true
+}
+
+// preloadOrderProduct loads an order's product even when it has since been
+// moved to the trash.
+func preloadOrderProduct(db *gorm.DB) *gorm.DB {
+	return db.Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+}
+
 func GetOrders(c *gin.Context) {
 	var orders []models.Order
-	database.DB.Preload("User").Preload("Product").Find(&orders)
+	preloadOrderProduct(database.DB.Preload("User")).Preload("Variant.Options").Find(&orders)
+
+	if !localizeOrders(c, orders) {
+		return
+	}
 	c.JSON(http.StatusOK, orders)
 }
 
@@ -17,44 +88,207 @@ func GetOrder(c *gin.Context) {
 	id := c.Param("id")
 	var order models.Order
 
-	if err := database.DB.Preload("User").Preload("Product").First(&order, id).Error; err != nil {
+	if err := preloadOrderProduct(database.DB.Preload("User")).Preload("Variant.Options").Where("id = ?", id).First(&order).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, order)
+	orders := []models.Order{order}
+	if !localizeOrders(c, orders) {
+		return
+	}
+	c.JSON(http.StatusOK, orders[0])
 }
 
 func CreateOrder(c *gin.Context) {
-	var order models.Order
+	var body orderPayload
 
-	if err := c.ShouldBindJSON(&order); err != nil {
+	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	order := models.Order{
+		ProductID:       body.ProductID,
+		VariantID:       body.VariantID,
+		Quantity:        body.Quantity,
+		ShippingAddress: body.ShippingAddress,
+		ShippingCountry: body.ShippingCountry,
+		BillingAddress:  body.BillingAddress,
+		BillingCountry:  body.BillingCountry,
+	}
+
+	if order.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be positive"})
+		return
+	}
+
+	var product models.Product
+	if err := database.DB.Scopes(services.PublishedProducts).First(&product, order.ProductID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var variantCount int64
+	database.DB.Model(&models.ProductVariant{}).Where("product_id = ?", product.ID).Count(&variantCount)
+
+	var variant models.ProductVariant
+	if order.VariantID != nil {
+		err := database.DB.Where("id = ? AND product_id = ?", *order.VariantID, product.ID).First(&variant).Error
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+			return
+		}
+		order.SKU = variant.SKU
+	} else if variantCount > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "variant_id is required for this product"})
+		return
+	}
+
+	// Orders are charged in the requested currency, or the product's own.
+	pricer, ok := newPricer(c, []uint{product.ID})
+	if !ok {
+		return
+	}
+	unitPrice, currency, err := pricer.VariantPrice(product, variant)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No exchange rate for " + product.Currency})
+		return
+	}
+
+	order.UnitPrice = unitPrice
+	order.Total = unitPrice * int64(order.Quantity)
+	order.Currency = currency
+	order.Status = models.OrderStatusPending
+	order.IPAddress = c.ClientIP()
+	if userID, ok := middleware.GetUserID(c); ok {
+		order.UserID = userID
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		check, err := services.ScreenOrder(tx, &order)
+		if err != nil {
+			return err
+		}
+		order.FraudScore = check.Score
+		if check.Held {
+			order.Status = models.OrderStatusOnHold
+		}
+
+		if err := services.ReserveStock(tx, &order); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Create(&order).Error; err != nil {
+			return err
+		}
+
+		check.OrderID = order.ID
+		if err := tx.Create(&check).Error; err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, services.EventOrderCreated, order)
+	})
+	if errors.Is(err, services.ErrOutOfStock) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Not enough stock"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+		return
+	}
 
-	database.DB.Create(&order)
 	c.JSON(http.StatusCreated, order)
 }
 
+// UpdateOrder lets customers correct the street addresses on their own
+// pending orders. Countries are fixed because fraud screening used them, and
+// status changes go through UpdateOrderStatus.
 func UpdateOrder(c *gin.Context) {
+	id := c.Param("id")
+	userID, _ := middleware.GetUserID(c)
+	var order models.Order
+
+	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&order).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	var body orderAddressPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if order.Status != models.OrderStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending orders can be changed"})
+		return
+	}
+
+	if body.ShippingAddress != nil {
+		order.ShippingAddress = *body.ShippingAddress
+	}
+	if body.BillingAddress != nil {
+		order.BillingAddress = *body.BillingAddress
+	}
+
+	err := database.DB.Model(&order).
+		Select("ShippingAddress", "BillingAddress").
+		Updates(&order).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// UpdateOrderStatus moves an order along its lifecycle: pending to paid,
+// shipped and delivered, or cancelled while pending or paid.
+func UpdateOrderStatus(c *gin.Context) {
 	id := c.Param("id")
 	var order models.Order
 
-	if err := database.DB.First(&order, id).Error; err != nil {
+	if err := database.DB.Where("id = ?", id).First(&order).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
 	}
 
-	c.ShouldBindJSON(&order)
-	database.DB.Save(&order)
+	var body orderStatusPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.ValidOrderStatus(body.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order status"})
+		return
+	}
+	// Held orders only leave review through the fraud review endpoint.
+	if order.Status == models.OrderStatusOnHold {
+		c.JSON(http.StatusConflict, gin.H{"error": "Order is held for fraud review"})
+		return
+	}
+
+	previousStatus := order.Status
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return services.SetOrderStatus(tx, &order, body.Status)
+	})
+	if errors.Is(err, services.ErrInvalidOrderTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": "An order cannot move from " + previousStatus + " to " + body.Status})
+		return
+	}
+	if errors.Is(err, services.ErrOrderStatusChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The order was changed by another request; reload and try again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save order"})
+		return
+	}
 
 	c.JSON(http.StatusOK, order)
 }
 
 func DeleteOrder(c *gin.Context) {
 	id := c.Param("id")
-	database.DB.Delete(&models.Order{}, id)
+	database.DB.Where("id = ?", id).Delete(&models.Order{})
 
 	c.JSON(http.StatusOK, gin.H{"message": "Order deleted"})
 }
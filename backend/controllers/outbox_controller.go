@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetOutboxEvents(c *gin.Context) {
+	var events []models.OutboxEvent
+
+	query := database.DB.Order("id DESC").Limit(100)
+	switch c.Query("status") {
+	case "pending":
+		query = query.Where("published_at IS NULL AND failed_at IS NULL")
+	case "published":
+		query = query.Where("published_at IS NOT NULL")
+	case "failed":
+		query = query.Where("failed_at IS NOT NULL")
+	}
+	if eventType := c.Query("type"); eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+	query.Find(&events)
+
+	c.JSON(http.StatusOK, events)
+}
+
+func RetryOutboxEvent(c *gin.Context) {
+	id := c.Param("id")
+	var event models.OutboxEvent
+
+	if err := database.DB.Where("id = ?", id).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	if event.FailedAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only failed events can be retried"})
+		return
+	}
+
+	if err := services.RetryEvent(&event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry event"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, event)
+}
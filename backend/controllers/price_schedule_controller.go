@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPriceHistoryLimit = 50
+	maxPriceHistoryLimit     = 200
+)
+
+type priceSchedulePayload struct {
+	Price    *int64     `json:"price" binding:"required,min=0"`
+	StartsAt time.Time  `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Note     string     `json:"note" binding:"max=500"`
+}
+
+func GetPriceSchedules(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product) {
+		return
+	}
+
+	var schedules []models.PriceSchedule
+	database.DB.Where("product_id = ?", product.ID).Order("starts_at DESC, id DESC").Find(&schedules)
+	c.JSON(http.StatusOK, schedules)
+}
+
+// CreatePriceSchedule schedules a price change for a product. With ends_at
+// it is a sale and the current price comes back afterwards. A start time
+// that has already passed applies the change straight away. Products with
+// per-currency or variant prices cannot be scheduled, as the schedule would
+// not change what those customers pay.
+func CreatePriceSchedule(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product) {
+		return
+	}
+
+	var body priceSchedulePayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	if body.EndsAt != nil && !body.EndsAt.After(body.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return
+	}
+	if body.EndsAt != nil && !body.EndsAt.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be in the future"})
+		return
+	}
+
+	schedule := models.PriceSchedule{
+		ProductID: product.ID,
+		Price:     *body.Price,
+		StartsAt:  body.StartsAt,
+		EndsAt:    body.EndsAt,
+		Note:      strings.TrimSpace(body.Note),
+		Status:    models.PriceScheduleScheduled,
+	}
+	if userID := adminID(c); userID != nil {
+		schedule.CreatedBy = *userID
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the product so concurrent requests cannot both pass the
+		// overlap check, and read the currency the price is in.
+		var locked models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "currency").First(&locked, product.ID).Error; err != nil {
+			return err
+		}
+		schedule.Currency = locked.Currency
+		if err := services.CheckPriceSchedulable(tx, schedule.ProductID); err != nil {
+			return err
+		}
+		if err := services.CheckPriceScheduleOverlap(tx, schedule); err != nil {
+			return err
+		}
+		return tx.Create(&schedule).Error
+	})
+	if errors.Is(err, services.ErrPriceScheduleOverlap) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The schedule overlaps another one for this product"})
+		return
+	}
+	if errors.Is(err, services.ErrPriceScheduleOverridden) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Products with per-currency or variant prices cannot have price schedules"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create price schedule"})
+		return
+	}
+
+	if !schedule.StartsAt.After(now) {
+		if err := services.ApplyDuePriceSchedules(now); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Price schedule saved but could not be applied yet"})
+			return
+		}
+		database.DB.First(&schedule, schedule.ID)
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// CancelPriceSchedule cancels a pending schedule, or ends a running sale
+// early and restores the price it replaced.
+func CancelPriceSchedule(c *gin.Context) {
+	var schedule models.PriceSchedule
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND product_id IN (?)", c.Param("scheduleId"), productIDQuery(c.Param("id"))).
+			First(&schedule).Error
+		if err != nil {
+			return err
+		}
+		if schedule.Status != models.PriceScheduleScheduled && schedule.Status != models.PriceScheduleActive {
+			return errPriceScheduleFinished
+		}
+		return services.EndPriceSchedule(tx, &schedule, models.PriceScheduleCancelled, time.Now())
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Price schedule not found"})
+		return
+	}
+	if errors.Is(err, errPriceScheduleFinished) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Price schedule is already " + schedule.Status})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel price schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+var errPriceScheduleFinished = errors.New("price schedule already finished")
+
+// GetPriceHistory lists the prices a product has had, newest first. Each
+// entry applies from changed_at until the one before it in the list.
+func GetPriceHistory(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product, services.PublishedProducts) {
+		return
+	}
+
+	page, limit, err := parsePage(c, defaultPriceHistoryLimit, maxPriceHistoryLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var total int64
+	database.DB.Model(&models.PriceChange{}).Where("product_id = ?", product.ID).Count(&total)
+
+	var changes []models.PriceChange
+	database.DB.Where("product_id = ?", product.ID).
+		Order("changed_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&changes)
+
+	c.JSON(http.StatusOK, gin.H{"data": changes, "total": total, "page": page, "limit": limit})
+}
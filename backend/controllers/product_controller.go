@@ -2,29 +2,188 @@ package controllers
 
 import (
 	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
 	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// presentProducts prepares products for a response: prices in the requested
+// currency and image URLs.
+func presentProducts(c *gin.Context, products []models.Product) bool {
+	if !localizeProducts(c, products) {
+		return false
+	}
+	attachImageURLs(c, products)
+	return true
+}
+
+// adminID returns the signed-in user's ID for audit fields.
+func adminID(c *gin.Context) *uint {
+	if userID, ok := middleware.GetUserID(c); ok {
+		return &userID
+	}
+	return nil
+}
+
+// productLookup narrows query to the product named by idOrSlug, which is
+// either its ID or its current slug.
+func productLookup(query *gorm.DB, idOrSlug string) *gorm.DB {
+	if id, err := strconv.ParseUint(idOrSlug, 10, 64); err == nil {
+		return query.Where("products.id = ?", id)
+	}
+	return query.Where("products.slug = ?", idOrSlug)
+}
+
+// redirectOldSlug answers a request naming a product by a slug it has since
+// replaced with a permanent redirect to the same route under its current
+// slug. scoped limits which products can be found, and it reports whether it
+// redirected.
+func redirectOldSlug(c *gin.Context, scoped *gorm.DB) bool {
+	var redirect models.ProductSlugRedirect
+	if err := database.DB.Where("slug = ?", c.Param("id")).First(&redirect).Error; err != nil {
+		return false
+	}
+	var product models.Product
+	if err := scoped.Select("id", "slug").First(&product, redirect.ProductID).Error; err != nil {
+		return false
+	}
+
+	location := strings.Replace(c.FullPath(), ":id", url.PathEscape(product.Slug), 1)
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusMovedPermanently, location)
+	return true
+}
+
+// findProduct loads the product named by the :id parameter, by ID or current
+// slug, within the given scopes. A read naming a replaced slug is redirected
+// and any other miss answers 404; it reports whether the product was found.
+func findProduct(c *gin.Context, product *models.Product, scopes ...func(*gorm.DB) *gorm.DB) bool {
+	if err := productLookup(database.DB.Scopes(scopes...), c.Param("id")).First(product).Error; err == nil {
+		return true
+	}
+	if c.Request.Method == http.MethodGet && redirectOldSlug(c, database.DB.Scopes(scopes...)) {
+		return false
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	return false
+}
+
+// productIDQuery selects the ID of the product named by idOrSlug, for
+// matching resources nested under it.
+func productIDQuery(idOrSlug string) *gorm.DB {
+	return productLookup(database.DB.Unscoped().Model(&models.Product{}).Select("products.id"), idOrSlug)
+}
+
+// bindFormStatus reads the status and publish_at form fields. publish_at is
+// an RFC 3339 time.
+func bindFormStatus(c *gin.Context, product *models.Product) bool {
+	if status := c.PostForm("status"); status != "" {
+		product.Status = status
+	}
+	if raw := c.PostForm("publish_at"); raw != "" {
+		publishAt, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at must be an RFC 3339 time"})
+			return false
+		}
+		product.PublishAt = &publishAt
+	}
+	return true
+}
+
+// bindFormAttributes reads the attributes form field, a JSON object of
+// attribute values.
+func bindFormAttributes(c *gin.Context, product *models.Product) bool {
+	raw := c.PostForm("attributes")
+	if raw == "" {
+		return true
+	}
+	var attributes models.ProductAttributes
+	if err := json.Unmarshal([]byte(raw), &attributes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "attributes must be a JSON object"})
+		return false
+	}
+	product.Attributes = attributes
+	return true
+}
+
 func GetProducts(c *gin.Context) {
+	q, err := parseProductQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filtered, err := parseProductFilters(c, database.DB.Model(&models.Product{}).Scopes(services.PublishedProducts))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count products"})
+		return
+	}
+
+	paged, err := q.apply(filtered.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		return
+	}
+
 	var products []models.Product
-	database.DB.Find(&products)
-	c.JSON(http.StatusOK, products)
+	if err := paged.Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load products"})
+		return
+	}
+	products, next := q.nextCursor(products)
+
+	if !presentProducts(c, products) {
+		return
+	}
+
+	response := gin.H{"data": products, "total": total, "limit": q.Limit, "sort": q.Sort, "next_cursor": next}
+	if q.cursor == nil {
+		response["page"] = q.Page
+	}
+	if !addFacets(c, response, filtered) {
+		return
+	}
+	c.JSON(http.StatusOK, response)
 }
 
+// GetProduct looks a product up by ID or slug. A slug the product no longer
+// uses redirects to its current one.
 func GetProduct(c *gin.Context) {
-	id := c.Param("id")
 	var product models.Product
 
-	if err := database.DB.First(&product, id).Error; err != nil {
+	query := preloadImages(preloadVariants(database.DB)).Scopes(services.PublishedProducts)
+	if err := productLookup(query, c.Param("id")).First(&product).Error; err != nil {
+		if redirectOldSlug(c, database.DB.Scopes(services.PublishedProducts)) {
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, product)
+	products := []models.Product{product}
+	if !presentProducts(c, products) {
+		return
+	}
+	c.JSON(http.StatusOK, products[0])
 }
 
 func CreateProduct(c *gin.Context) {
@@ -39,41 +198,131 @@ func CreateProduct(c *gin.Context) {
 		}
 
 		product.Title = c.PostForm("title")
+		product.Slug = c.PostForm("slug")
+		if sku := c.PostForm("sku"); sku != "" {
+			product.SKU = &sku
+		}
 		product.Description = c.PostForm("description")
 		priceStr := c.PostForm("price")
 		if priceStr != "" {
-			price, _ := strconv.Atoi(priceStr)
+			price, _ := strconv.ParseInt(priceStr, 10, 64)
 			product.Price = price
 		}
+		product.Currency = c.PostForm("currency")
 		product.Category = c.PostForm("category")
+		if categoryID, err := strconv.ParseUint(c.PostForm("category_id"), 10, 64); err == nil {
+			id := uint(categoryID)
+			product.CategoryID = &id
+		}
+		if stockStr := c.PostForm("stock"); stockStr != "" {
+			stock, _ := strconv.Atoi(stockStr)
+			product.Stock = &stock
+		}
+		if !bindFormAttributes(c, &product) || !bindFormStatus(c, &product) {
+			return
+		}
 
 		file, err := c.FormFile("image")
 		if err == nil {
-			filename := "uploads/" + file.Filename
-			if err := c.SaveUploadedFile(file, filename); err == nil {
-				product.Image = filename
+			filename, err := saveUpload(c, file)
+			if err != nil {
+				uploadError(c, err)
+				return
 			}
+			product.Image = filename
 		}
 	} else {
 		if err := c.ShouldBindJSON(&product); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		// Review aggregates are maintained by the server.
+		product.RatingAverage, product.ReviewCount = 0, 0
+	}
+
+	product.Currency = services.NormalizeCurrency(product.Currency)
+	if product.Currency == "" {
+		product.Currency = services.BaseCurrency()
+	}
+	if !services.ValidCurrency(product.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid currency code"})
+		return
+	}
+	if err := services.ApplyProductStatus(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.CheckCurrencyRate(tx, product.Currency); err != nil {
+			return err
+		}
+		if err := services.ApplyProductCategory(tx, &product); err != nil {
+			return err
+		}
+		if err := services.ApplyProductSKU(tx, &product); err != nil {
+			return err
+		}
+		if err := services.ApplyProductSlug(tx, &product, product.Slug); err != nil {
+			return err
+		}
+		if err := services.ApplyProductAttributes(tx, &product); err != nil {
+			return err
+		}
+		// Options and variants are managed through their own endpoints.
+		if err := tx.Omit(clause.Associations).Create(&product).Error; err != nil {
+			return err
+		}
+		if err := services.RecordPrice(tx, product, models.PriceSourceCreated, nil, adminID(c)); err != nil {
+			return err
+		}
+		if err := services.SetPrimaryImagePath(tx, product); err != nil {
+			return err
+		}
+		if err := services.RecordEvent(tx, services.EventProductCreated, product); err != nil {
+			return err
+		}
+		return services.RecordVisibilityChange(tx, false, product)
+	})
+	if errors.Is(err, services.ErrUnknownCurrency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No exchange rate for " + product.Currency})
+		return
+	}
+	if errors.Is(err, services.ErrCategoryNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+		return
+	}
+	if errors.Is(err, services.ErrSKUTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "SKU already in use"})
+		return
+	}
+	if errors.Is(err, services.ErrSlugTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug already in use"})
+		return
+	}
+	var attributeErr *services.AttributeError
+	if errors.As(err, &attributeErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product"})
+		return
 	}
 
-	database.DB.Create(&product)
 	c.JSON(http.StatusCreated, product)
 }
 
 func UpdateProduct(c *gin.Context) {
-	id := c.Param("id")
 	var product models.Product
-
-	if err := database.DB.First(&product, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	if !findProduct(c, &product) {
 		return
 	}
+	previousTitle := product.Title
+	wasPublished := services.IsPublished(product, time.Now())
 
+	var requestedSlug string
+	var priceSet bool
 	contentType := c.GetHeader("Content-Type")
 
 	if strings.Contains(contentType, "multipart/form-data") {
@@ -85,23 +334,46 @@ func UpdateProduct(c *gin.Context) {
 		if title := c.PostForm("title"); title != "" {
 			product.Title = title
 		}
+		requestedSlug = c.PostForm("slug")
+		if sku := c.PostForm("sku"); sku != "" {
+			product.SKU = &sku
+		}
 		if description := c.PostForm("description"); description != "" {
 			product.Description = description
 		}
 		if priceStr := c.PostForm("price"); priceStr != "" {
-			price, _ := strconv.Atoi(priceStr)
+			price, _ := strconv.ParseInt(priceStr, 10, 64)
 			product.Price = price
+			priceSet = true
+		}
+		if currency := c.PostForm("currency"); currency != "" {
+			product.Currency = currency
+			priceSet = true
 		}
 		if category := c.PostForm("category"); category != "" {
 			product.Category = category
+			product.CategoryID = nil
+		}
+		if categoryID, err := strconv.ParseUint(c.PostForm("category_id"), 10, 64); err == nil {
+			id := uint(categoryID)
+			product.CategoryID = &id
+		}
+		if stockStr := c.PostForm("stock"); stockStr != "" {
+			stock, _ := strconv.Atoi(stockStr)
+			product.Stock = &stock
+		}
+		if !bindFormAttributes(c, &product) || !bindFormStatus(c, &product) {
+			return
 		}
 
 		file, err := c.FormFile("image")
 		if err == nil {
-			filename := "uploads/" + file.Filename
-			if err := c.SaveUploadedFile(file, filename); err == nil {
-				product.Image = filename
+			filename, err := saveUpload(c, file)
+			if err != nil {
+				uploadError(c, err)
+				return
 			}
+			product.Image = filename
 		}
 	} else {
 		var updateData models.Product
@@ -113,21 +385,126 @@ func UpdateProduct(c *gin.Context) {
 		if updateData.Title != "" {
 			product.Title = updateData.Title
 		}
+		requestedSlug = updateData.Slug
+		if updateData.SKU != nil {
+			product.SKU = updateData.SKU
+		}
 		if updateData.Description != "" {
 			product.Description = updateData.Description
 		}
 		if updateData.Price != 0 {
 			product.Price = updateData.Price
+			priceSet = true
+		}
+		if updateData.Currency != "" {
+			product.Currency = updateData.Currency
+			priceSet = true
 		}
 		if updateData.Category != "" {
 			product.Category = updateData.Category
+			product.CategoryID = nil
+		}
+		if updateData.CategoryID != nil {
+			product.CategoryID = updateData.CategoryID
 		}
 		if updateData.Image != "" {
 			product.Image = updateData.Image
 		}
+		if updateData.Stock != nil {
+			product.Stock = updateData.Stock
+		}
+		if updateData.Attributes != nil {
+			product.Attributes = updateData.Attributes
+		}
+		if updateData.Status != "" {
+			product.Status = updateData.Status
+		}
+		if updateData.PublishAt != nil {
+			product.PublishAt = updateData.PublishAt
+		}
 	}
 
-	if err := database.DB.Save(&product).Error; err != nil {
+	product.Currency = services.NormalizeCurrency(product.Currency)
+	if !services.ValidCurrency(product.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid currency code"})
+		return
+	}
+	if err := services.ApplyProductStatus(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// The price scheduler may have changed the price since the product
+		// was loaded. Lock the row and keep the current price unless this
+		// request sets one.
+		var current models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "price", "currency").First(&current, product.ID).Error; err != nil {
+			return err
+		}
+		if !priceSet {
+			product.Price, product.Currency = current.Price, current.Currency
+		}
+		if product.Currency != current.Currency {
+			if err := services.CheckCurrencyRate(tx, product.Currency); err != nil {
+				return err
+			}
+		}
+
+		if err := services.ApplyProductCategory(tx, &product); err != nil {
+			return err
+		}
+		if err := services.ApplyProductSKU(tx, &product); err != nil {
+			return err
+		}
+		// The slug follows the title; the old one becomes a redirect.
+		if product.Title != previousTitle || requestedSlug != "" {
+			if err := services.ApplyProductSlug(tx, &product, requestedSlug); err != nil {
+				return err
+			}
+		}
+		if err := services.ApplyProductAttributes(tx, &product); err != nil {
+			return err
+		}
+		// Review aggregates may have moved since the product was loaded.
+		if err := tx.Omit(clause.Associations, "RatingAverage", "ReviewCount").Save(&product).Error; err != nil {
+			return err
+		}
+		if product.Price != current.Price || product.Currency != current.Currency {
+			if err := services.RecordPrice(tx, product, models.PriceSourceManual, nil, adminID(c)); err != nil {
+				return err
+			}
+		}
+		if err := services.SetPrimaryImagePath(tx, product); err != nil {
+			return err
+		}
+		if err := services.RecordEvent(tx, services.EventProductUpdated, product); err != nil {
+			return err
+		}
+		return services.RecordVisibilityChange(tx, wasPublished, product)
+	})
+	if errors.Is(err, services.ErrUnknownCurrency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No exchange rate for " + product.Currency})
+		return
+	}
+	if errors.Is(err, services.ErrCategoryNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+		return
+	}
+	if errors.Is(err, services.ErrSKUTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "SKU already in use"})
+		return
+	}
+	if errors.Is(err, services.ErrSlugTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug already in use"})
+		return
+	}
+	var attributeErr *services.AttributeError
+	if errors.As(err, &attributeErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save product"})
 		return
 	}
@@ -136,8 +513,149 @@ func UpdateProduct(c *gin.Context) {
 }
 
 func DeleteProduct(c *gin.Context) {
-	id := c.Param("id")
-	database.DB.Delete(&models.Product{}, id)
+	var product models.Product
+	if !findProduct(c, &product) {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&product).Error; err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, services.EventProductDeleted, product)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product moved to trash"})
+}
+
+// GetTrashedProducts lists deleted products, most recently deleted first.
+// Products trashed for longer than services.TrashRetention may have had
+// their images cleaned up.
+func GetTrashedProducts(c *gin.Context) {
+	var products []models.Product
+	database.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&products)
+
+	attachImageURLs(c, products)
+	c.JSON(http.StatusOK, products)
+}
+
+func RestoreProduct(c *gin.Context) {
+	var product models.Product
+
+	if err := productLookup(database.DB.Unscoped().Where("deleted_at IS NOT NULL"), c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found in trash"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&product).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		product.DeletedAt = gorm.DeletedAt{}
+		return services.RecordEvent(tx, services.EventProductRestored, product)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// GetUnpublishedProducts lists products the public cannot see, newest first.
+// status narrows it to draft, archived, or scheduled (drafts with a publish
+// time).
+func GetUnpublishedProducts(c *gin.Context) {
+	page, limit, err := parsePage(c, defaultProductLimit, maxProductLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Model(&models.Product{}).Where("NOT " + services.PublishedSQL("products"))
+	switch c.Query("status") {
+	case "":
+	case models.ProductStatusDraft, models.ProductStatusArchived:
+		query = query.Where("status = ?", c.Query("status"))
+	case "scheduled":
+		query = query.Where("status = ? AND publish_at IS NOT NULL", models.ProductStatusDraft)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be draft, archived or scheduled"})
+		return
+	}
+
+	var total int64
+	query.Session(&gorm.Session{}).Count(&total)
+
+	var products []models.Product
+	query.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&products)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Product deleted"})
-}
\ No newline at end of file
+	attachImageURLs(c, products)
+	c.JSON(http.StatusOK, gin.H{"data": products, "total": total, "page": page, "limit": limit})
+}
+
+// PreviewProduct shows a product as GetProduct would, whatever its status.
+func PreviewProduct(c *gin.Context) {
+	var product models.Product
+
+	if err := productLookup(preloadImages(preloadVariants(database.DB)), c.Param("id")).First(&product).Error; err != nil {
+		if redirectOldSlug(c, database.DB) {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	products := []models.Product{product}
+	if !presentProducts(c, products) {
+		return
+	}
+	c.JSON(http.StatusOK, products[0])
+}
+
+type productStatusPayload struct {
+	Status    string     `json:"status" binding:"required"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// SetProductStatus publishes, unpublishes or archives a product. A draft
+// with publish_at is published automatically at that time.
+func SetProductStatus(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product) {
+		return
+	}
+
+	var body productStatusPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wasPublished := services.IsPublished(product, time.Now())
+	product.Status, product.PublishAt = body.Status, body.PublishAt
+	if err := services.ApplyProductStatus(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&product).Select("Status", "PublishAt").Updates(&product).Error; err != nil {
+			return err
+		}
+		if err := services.RecordEvent(tx, services.EventProductUpdated, product); err != nil {
+			return err
+		}
+		return services.RecordVisibilityChange(tx, wasPublished, product)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultProductLimit = 20
+	maxProductLimit     = 100
+)
+
+// productSort orders by column, or by an expression computed per row. A
+// cursor stores the last row's value for a column sort; for an expression it
+// only stores the ID and the value is computed again from that row.
+type productSort struct {
+	column string
+	expr   func(table string) string
+	desc   bool
+	value  func(p models.Product) interface{}
+}
+
+// Prices sort in the base currency so products priced in different
+// currencies are compared fairly.
+var productSorts = map[string]productSort{
+	"newest":     {column: "products.id", desc: true},
+	"price_asc":  {expr: services.BasePriceSQL},
+	"price_desc": {expr: services.BasePriceSQL, desc: true},
+	"title_asc":  {column: "products.title", value: func(p models.Product) interface{} { return p.Title }},
+	"title_desc": {column: "products.title", desc: true, value: func(p models.Product) interface{} { return p.Title }},
+	"rating":     {column: "products.rating_average", desc: true, value: func(p models.Product) interface{} { return p.RatingAverage }},
+}
+
+// productCursor marks the last row of a page for keyset pagination. The sort
+// is stored so a cursor cannot be replayed against a different ordering.
+type productCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v,omitempty"`
+	ID    uint            `json:"id"`
+}
+
+type productQuery struct {
+	Page   int
+	Limit  int
+	Sort   string
+	cursor *productCursor
+}
+
+// productInStock matches products that can be bought: those without variants
+// when the product has stock, and those with variants when one of them has
+// stock of its own or draws on the product's.
+const productInStock = `(
+	(NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.id)
+		AND (products.stock IS NULL OR products.stock > 0))
+	OR EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.id
+		AND (v.stock > 0 OR (v.stock IS NULL AND (products.stock IS NULL OR products.stock > 0)))))`
+
+// parseProductFilters applies the catalog filters shared by listing and
+// counting: category (including subcategories), price range, availability
+// and attribute values. Prices are minor units of the requested currency, or
+// of the base currency, and are compared in the base currency.
+func parseProductFilters(c *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	if category := c.Query("category"); category != "" {
+		ids, err := categoryFilterIDs(category)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("products.category_id IN ?", ids)
+	}
+	for _, bound := range []struct{ param, op string }{{"min_price", ">="}, {"max_price", "<="}} {
+		v := c.Query(bound.param)
+		if v == "" {
+			continue
+		}
+		price, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", bound.param)
+		}
+		price, err = basePrice(c, price)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(services.BasePriceSQL("products")+" "+bound.op+" ?", price)
+	}
+	switch c.Query("in_stock") {
+	case "":
+	case "true":
+		query = query.Where(productInStock)
+	case "false":
+		query = query.Where("NOT " + productInStock)
+	default:
+		return nil, errors.New("in_stock must be true or false")
+	}
+	if filters := c.QueryMap("attr"); len(filters) > 0 {
+		return attributeFilters(query, filters)
+	}
+	return query, nil
+}
+
+// basePrice converts a price filter from the requested currency to the base
+// currency.
+func basePrice(c *gin.Context, price int64) (int64, error) {
+	currency := requestedCurrency(c)
+	if currency == "" {
+		return price, nil
+	}
+	rates, err := services.LoadRates()
+	if err != nil {
+		return 0, errors.New("failed to load exchange rates")
+	}
+	price, err = rates.Convert(price, currency, services.BaseCurrency())
+	if err != nil {
+		return 0, errors.New("unsupported currency")
+	}
+	return price, nil
+}
+
+// attributeFilters applies attr[slug]=value filters. Comma-separated values
+// match any of them, and number attributes also take ranges such as 13..16,
+// 13.. or ..16. Different attributes must all match.
+func attributeFilters(query *gorm.DB, filters map[string]string) (*gorm.DB, error) {
+	slugs := make([]string, 0, len(filters))
+	for slug := range filters {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	for _, slug := range slugs {
+		var attribute models.Attribute
+		if err := database.DB.Where("slug = ?", slug).First(&attribute).Error; err != nil {
+			return nil, fmt.Errorf("unknown attribute %s", slug)
+		}
+
+		var conditions []string
+		var args []interface{}
+		for _, raw := range strings.Split(filters[slug], ",") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			if attribute.Type == models.AttributeNumber && strings.Contains(raw, "..") {
+				condition, rangeArgs, err := attributeRange(slug, raw)
+				if err != nil {
+					return nil, err
+				}
+				conditions = append(conditions, condition)
+				args = append(args, rangeArgs...)
+				continue
+			}
+
+			value, err := attributeFilterValue(attribute, raw)
+			if err != nil {
+				return nil, err
+			}
+			contains, _ := json.Marshal(map[string]interface{}{slug: value})
+			conditions = append(conditions, "products.attributes @> ?::jsonb")
+			args = append(args, string(contains))
+		}
+		if len(conditions) == 0 {
+			return nil, fmt.Errorf("attr[%s] needs a value", slug)
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	return query, nil
+}
+
+func attributeFilterValue(attribute models.Attribute, raw string) (interface{}, error) {
+	switch attribute.Type {
+	case models.AttributeNumber:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("attr[%s] must be a number or a range", attribute.Slug)
+		}
+		return number, nil
+	case models.AttributeBoolean:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("attr[%s] must be true or false", attribute.Slug)
+		}
+		return flag, nil
+	default:
+		return services.ResolveAttributeText(attribute, raw)
+	}
+}
+
+// attributeRange turns "min..max", with either end optional, into a numeric
+// comparison on the attribute.
+func attributeRange(slug, raw string) (string, []interface{}, error) {
+	bounds := strings.SplitN(raw, "..", 2)
+	invalid := fmt.Errorf("attr[%s] has an invalid range %q", slug, raw)
+
+	var conditions []string
+	var args []interface{}
+	for i, op := range []string{">=", "<="} {
+		bound := strings.TrimSpace(bounds[i])
+		if bound == "" {
+			continue
+		}
+		number, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return "", nil, invalid
+		}
+		conditions = append(conditions, "(products.attributes->>?)::numeric "+op+" ?")
+		args = append(args, slug, number)
+	}
+	if len(conditions) == 0 {
+		return "", nil, invalid
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")", args, nil
+}
+
+// addFacets adds attribute facet counts for the filtered result set to a
+// catalog response when the client asks for them with facets=true.
+func addFacets(c *gin.Context, response gin.H, filtered *gorm.DB) bool {
+	if c.Query("facets") != "true" {
+		return true
+	}
+
+	facets, err := services.AttributeFacets(database.DB, filtered.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count facets"})
+		return false
+	}
+	response["facets"] = facets
+	return true
+}
+
+// categoryFilterIDs resolves a category given by ID, slug or name to the IDs
+// of it and its descendants. Unknown categories match nothing.
+func categoryFilterIDs(category string) ([]uint, error) {
+	var found models.Category
+	if id, err := strconv.ParseUint(category, 10, 64); err == nil {
+		err = database.DB.First(&found, id).Error
+		if err != nil {
+			return []uint{0}, nil
+		}
+	} else if err := database.DB.Where("slug = ?", services.CategorySlug(category)).First(&found).Error; err != nil {
+		return []uint{0}, nil
+	}
+
+	ids, err := services.DescendantCategoryIDs(database.DB, found.ID)
+	if err != nil {
+		return nil, errors.New("failed to load categories")
+	}
+	return ids, nil
+}
+
+func parseProductQuery(c *gin.Context) (productQuery, error) {
+	q := productQuery{Page: 1, Limit: defaultProductLimit, Sort: c.DefaultQuery("sort", "newest")}
+
+	if _, ok := productSorts[q.Sort]; !ok {
+		return q, errors.New("sort must be one of newest, price_asc, price_desc, title_asc, title_desc, rating")
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxProductLimit {
+			return q, fmt.Errorf("limit must be between 1 and %d", maxProductLimit)
+		}
+		q.Limit = limit
+	}
+	if v := c.Query("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return q, errors.New("page must be a positive integer")
+		}
+		q.Page = page
+	}
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := decodeProductCursor(v)
+		if err != nil || cursor.Sort != q.Sort {
+			return q, errors.New("invalid cursor")
+		}
+		q.cursor = &cursor
+	}
+	return q, nil
+}
+
+// apply adds ordering and either the keyset condition or an offset.
+func (q productQuery) apply(query *gorm.DB) (*gorm.DB, error) {
+	sort := productSorts[q.Sort]
+	dir, cmp := "ASC", ">"
+	if sort.desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	column := sort.column
+	if sort.expr != nil {
+		column = sort.expr("products")
+	}
+
+	if q.cursor != nil {
+		switch {
+		case sort.expr != nil:
+			query = query.Where(fmt.Sprintf("(%s, products.id) %s (SELECT %s, p.id FROM products p WHERE p.id = ?)",
+				column, cmp, sort.expr("p")), q.cursor.ID)
+		case sort.value == nil:
+			query = query.Where(fmt.Sprintf("products.id %s ?", cmp), q.cursor.ID)
+		default:
+			value, err := decodeCursorValue(q.Sort, q.cursor.Value)
+			if err != nil {
+				return nil, err
+			}
+			query = query.Where(fmt.Sprintf("(%s, products.id) %s (?, ?)", column, cmp), value, q.cursor.ID)
+		}
+	} else {
+		query = query.Offset((q.Page - 1) * q.Limit)
+	}
+
+	if sort.value != nil || sort.expr != nil {
+		query = query.Order(fmt.Sprintf("%s %s", column, dir))
+	}
+	return query.Order("products.id " + dir).Limit(q.Limit + 1), nil
+}
+
+// nextCursor trims the look-ahead row and returns the cursor for the next
+// page, or "" when this is the last one.
+func (q productQuery) nextCursor(products []models.Product) ([]models.Product, string) {
+	if len(products) <= q.Limit {
+		return products, ""
+	}
+	products = products[:q.Limit]
+	last := products[len(products)-1]
+
+	cursor := productCursor{Sort: q.Sort, ID: last.ID}
+	if sort := productSorts[q.Sort]; sort.value != nil {
+		cursor.Value, _ = json.Marshal(sort.value(last))
+	}
+	raw, _ := json.Marshal(cursor)
+	return products, base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeProductCursor(encoded string) (productCursor, error) {
+	var cursor productCursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(raw, &cursor)
+	return cursor, err
+}
+
+func decodeCursorValue(sort string, raw json.RawMessage) (interface{}, error) {
+	switch sort {
+	case "rating":
+		var rating float64
+		err := json.Unmarshal(raw, &rating)
+		return rating, err
+	default:
+		var title string
+		err := json.Unmarshal(raw, &title)
+		return title, err
+	}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"ecommerce/backend/models"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func testProducts(n int) []models.Product {
+	products := make([]models.Product, n)
+	for i := range products {
+		products[i] = models.Product{ID: uint(i + 1), Title: "Product", Price: int64(100 * (i + 1)), RatingAverage: 4.5}
+	}
+	return products
+}
+
+func TestNextCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		sort  string
+		value interface{}
+	}{
+		{"newest", nil},
+		{"price_asc", nil},
+		{"price_desc", nil},
+		{"title_asc", "Product"},
+		{"rating", 4.5},
+	}
+	for _, tt := range tests {
+		q := productQuery{Limit: 2, Sort: tt.sort}
+		page, encoded := q.nextCursor(testProducts(3))
+		if len(page) != 2 {
+			t.Fatalf("%s: page has %d products, want 2", tt.sort, len(page))
+		}
+		if encoded == "" {
+			t.Fatalf("%s: no cursor for a page with more results", tt.sort)
+		}
+
+		cursor, err := decodeProductCursor(encoded)
+		if err != nil {
+			t.Fatalf("%s: decodeProductCursor: %v", tt.sort, err)
+		}
+		if cursor.Sort != tt.sort || cursor.ID != 2 {
+			t.Errorf("%s: cursor = %+v, want sort %s and id 2", tt.sort, cursor, tt.sort)
+		}
+		if tt.value == nil {
+			if cursor.Value != nil {
+				t.Errorf("%s: cursor has value %s, want none", tt.sort, cursor.Value)
+			}
+			continue
+		}
+
+		value, err := decodeCursorValue(tt.sort, cursor.Value)
+		if err != nil {
+			t.Fatalf("%s: decodeCursorValue: %v", tt.sort, err)
+		}
+		if value != tt.value {
+			t.Errorf("%s: value = %#v, want %#v", tt.sort, value, tt.value)
+		}
+	}
+}
+
+func TestNextCursorLastPage(t *testing.T) {
+	q := productQuery{Limit: 3, Sort: "newest"}
+	page, encoded := q.nextCursor(testProducts(3))
+	if len(page) != 3 || encoded != "" {
+		t.Errorf("got %d products and cursor %q, want 3 and no cursor", len(page), encoded)
+	}
+}
+
+func TestDecodeProductCursorRejectsGarbage(t *testing.T) {
+	for _, encoded := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, err := decodeProductCursor(encoded); err == nil {
+			t.Errorf("decodeProductCursor(%q) succeeded, want an error", encoded)
+		}
+	}
+}
+
+func TestParseProductQueryRejectsCursorForOtherSort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, encoded := productQuery{Limit: 1, Sort: "price_asc"}.nextCursor(testProducts(2))
+
+	parse := func(sort string) error {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		query := url.Values{"sort": {sort}, "cursor": {encoded}}
+		c.Request = httptest.NewRequest("GET", "/products?"+query.Encode(), nil)
+		_, err := parseProductQuery(c)
+		return err
+	}
+
+	if err := parse("price_asc"); err != nil {
+		t.Errorf("cursor rejected for its own sort: %v", err)
+	}
+	if err := parse("title_asc"); err == nil {
+		t.Error("cursor accepted for a different sort")
+	}
+}
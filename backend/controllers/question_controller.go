@@ -0,0 +1,346 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strings"
+)
+
+type questionPayload struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+func questionsWithAuthor(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.ProductQuestion{}).
+		Select("product_questions.*, users.name AS author_name").
+		Joins("LEFT JOIN users ON users.id = product_questions.user_id")
+}
+
+func answersWithAuthor(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.ProductAnswer{}).
+		Select("product_answers.*, users.name AS author_name").
+		Joins("LEFT JOIN users ON users.id = product_answers.user_id")
+}
+
+// findProductQuestion loads an approved question of the product named in the
+// URL.
+func findProductQuestion(c *gin.Context) (models.ProductQuestion, bool) {
+	var question models.ProductQuestion
+	err := questionsWithAuthor(database.DB).
+		Where("product_questions.id = ? AND product_questions.product_id IN (?) AND product_questions.status = ?",
+			c.Param("questionId"), productIDQuery(c.Param("id")), models.ModerationApproved).
+		First(&question).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return question, false
+	}
+	return question, true
+}
+
+func findQuestionAnswer(c *gin.Context) (models.ProductAnswer, bool) {
+	question, ok := findProductQuestion(c)
+	if !ok {
+		return models.ProductAnswer{}, false
+	}
+
+	var answer models.ProductAnswer
+	err := database.DB.
+		Where("id = ? AND question_id = ? AND status = ?", c.Param("answerId"), question.ID, models.ModerationApproved).
+		First(&answer).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Answer not found"})
+		return answer, false
+	}
+	return answer, true
+}
+
+// GetProductQuestions lists approved questions, newest first, each with its
+// approved answers. Answers from admins come first, then the most upvoted.
+func GetProductQuestions(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product, services.PublishedProducts) {
+		return
+	}
+
+	page, limit, err := parsePage(c, defaultReviewLimit, maxReviewLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var total int64
+	err = database.DB.Model(&models.ProductQuestion{}).
+		Where("product_id = ? AND status = ?", product.ID, models.ModerationApproved).
+		Count(&total).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load questions"})
+		return
+	}
+
+	var questions []models.ProductQuestion
+	err = questionsWithAuthor(database.DB).
+		Where("product_questions.product_id = ? AND product_questions.status = ?", product.ID, models.ModerationApproved).
+		Preload("Answers", func(db *gorm.DB) *gorm.DB {
+			return answersWithAuthor(db).
+				Where("product_answers.status = ?", models.ModerationApproved).
+				Order("product_answers.by_admin DESC, product_answers.upvote_count DESC, product_answers.id")
+		}).
+		Order("product_questions.id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&questions).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load questions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": questions, "total": total, "page": page, "limit": limit})
+}
+
+func CreateProductQuestion(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product, services.PublishedProducts) {
+		return
+	}
+
+	var body questionPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	question := models.ProductQuestion{ProductID: product.ID, UserID: userID, Body: strings.TrimSpace(body.Body)}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.ScreenContent(tx, &question.Moderation, question.Body, false); err != nil {
+			return err
+		}
+		return tx.Create(&question).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save question"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, question)
+}
+
+// CreateQuestionAnswer answers an approved question. Only admins and
+// customers with a delivered order for the product may answer.
+func CreateQuestionAnswer(c *gin.Context) {
+	question, ok := findProductQuestion(c)
+	if !ok {
+		return
+	}
+
+	var body questionPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	answer := models.ProductAnswer{QuestionID: question.ID, UserID: userID, Body: strings.TrimSpace(body.Body)}
+
+	answer.ByAdmin = isAdmin(userID)
+	if !answer.ByAdmin {
+		_, err := services.DeliveredOrderID(database.DB, userID, question.ProductID)
+		if errors.Is(err, services.ErrNotVerifiedBuyer) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and customers who received this product can answer"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check orders"})
+			return
+		}
+		answer.VerifiedBuyer = true
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.ScreenContent(tx, &answer.Moderation, answer.Body, answer.ByAdmin); err != nil {
+			return err
+		}
+		if err := tx.Create(&answer).Error; err != nil {
+			return err
+		}
+		if answer.Status == models.ModerationApproved {
+			return services.NotifyAnswered(tx, &answer)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save answer"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, answer)
+}
+
+// UpvoteAnswer records the user's upvote. Upvoting twice has no effect.
+func UpvoteAnswer(c *gin.Context) {
+	answer, ok := findQuestionAnswer(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	if answer.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot upvote your own answer"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.AnswerVote{AnswerID: answer.ID, UserID: userID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.ProductAnswer{}).Where("id = ?", answer.ID).
+			UpdateColumn("upvote_count", gorm.Expr("upvote_count + 1")).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save vote"})
+		return
+	}
+
+	answerUpvoteCount(c, answer.ID)
+}
+
+func RemoveAnswerUpvote(c *gin.Context) {
+	answer, ok := findQuestionAnswer(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("answer_id = ? AND user_id = ?", answer.ID, userID).Delete(&models.AnswerVote{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.ProductAnswer{}).Where("id = ?", answer.ID).
+			UpdateColumn("upvote_count", gorm.Expr("upvote_count - 1")).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove vote"})
+		return
+	}
+
+	answerUpvoteCount(c, answer.ID)
+}
+
+func answerUpvoteCount(c *gin.Context, answerID uint) {
+	var count int
+	database.DB.Model(&models.ProductAnswer{}).Where("id = ?", answerID).Select("upvote_count").Scan(&count)
+	c.JSON(http.StatusOK, gin.H{"answer_id": answerID, "upvote_count": count})
+}
+
+func GetQuestionQueue(c *gin.Context) {
+	status, page, limit, ok := parseModerationQueue(c)
+	if !ok {
+		return
+	}
+
+	var total int64
+	database.DB.Model(&models.ProductQuestion{}).Where("status = ?", status).Count(&total)
+
+	var questions []models.ProductQuestion
+	questionsWithAuthor(database.DB).
+		Where("product_questions.status = ?", status).
+		Order("product_questions.id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&questions)
+
+	c.JSON(http.StatusOK, gin.H{"data": questions, "total": total, "page": page, "limit": limit, "status": status})
+}
+
+func GetAnswerQueue(c *gin.Context) {
+	status, page, limit, ok := parseModerationQueue(c)
+	if !ok {
+		return
+	}
+
+	var total int64
+	database.DB.Model(&models.ProductAnswer{}).Where("status = ?", status).Count(&total)
+
+	var answers []models.ProductAnswer
+	answersWithAuthor(database.DB).
+		Where("product_answers.status = ?", status).
+		Order("product_answers.id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&answers)
+
+	c.JSON(http.StatusOK, gin.H{"data": answers, "total": total, "page": page, "limit": limit, "status": status})
+}
+
+func ModerateQuestion(c *gin.Context) {
+	id := c.Param("id")
+	var question models.ProductQuestion
+
+	if err := questionsWithAuthor(database.DB).Where("product_questions.id = ?", id).First(&question).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+
+	var body moderationPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if question.Status == body.Decision {
+		c.JSON(http.StatusConflict, gin.H{"error": "Question is already " + body.Decision})
+		return
+	}
+
+	moderator, _ := middleware.GetUserID(c)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return services.ModerateQuestion(tx, &question, body.Decision, strings.TrimSpace(body.Note), moderator)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save decision"})
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+func ModerateAnswer(c *gin.Context) {
+	id := c.Param("id")
+	var answer models.ProductAnswer
+
+	if err := answersWithAuthor(database.DB).Where("product_answers.id = ?", id).First(&answer).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Answer not found"})
+		return
+	}
+
+	var body moderationPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if answer.Status == body.Decision {
+		c.JSON(http.StatusConflict, gin.H{"error": "Answer is already " + body.Decision})
+		return
+	}
+
+	moderator, _ := middleware.GetUserID(c)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return services.ModerateAnswer(tx, &answer, body.Decision, strings.TrimSpace(body.Note), moderator)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save decision"})
+		return
+	}
+
+	c.JSON(http.StatusOK, answer)
+}
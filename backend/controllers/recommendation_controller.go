@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultRecommendationLimit = 8
+	maxRecommendationLimit     = 24
+)
+
+type recommendation struct {
+	Product models.Product `json:"product"`
+	// Reason is bought_together or best_seller; Score is the number of
+	// customers who bought both, or units sold for best sellers.
+	Reason string `json:"reason"`
+	Score  int64  `json:"score"`
+}
+
+// respondRecommendations looks up and presents recommendations for the given
+// products.
+func respondRecommendations(c *gin.Context, productIDs []uint) {
+	limit := defaultRecommendationLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxRecommendationLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxRecommendationLimit)})
+			return
+		}
+		limit = n
+	}
+
+	scored, err := services.Recommend(database.DB, productIDs, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load recommendations"})
+		return
+	}
+
+	ids := make([]uint, len(scored))
+	for i, s := range scored {
+		ids[i] = s.ProductID
+	}
+	var products []models.Product
+	database.DB.Scopes(services.PublishedProducts).Where("id IN ?", ids).Find(&products)
+	if !presentProducts(c, products) {
+		return
+	}
+	byID := map[uint]models.Product{}
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	data := []recommendation{}
+	for _, s := range scored {
+		if product, ok := byID[s.ProductID]; ok {
+			data = append(data, recommendation{Product: product, Reason: s.Reason, Score: s.Score})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// GetProductRecommendations suggests products frequently bought with this
+// one, topped up with best sellers from its category.
+func GetProductRecommendations(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product, services.PublishedProducts) {
+		return
+	}
+
+	respondRecommendations(c, []uint{product.ID})
+}
+
+// GetCartRecommendations suggests products to go with everything in the
+// current user's cart.
+func GetCartRecommendations(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var productIDs []uint
+	database.DB.Model(&models.CartItem{}).Where("user_id = ?", userID).Distinct().Pluck("product_id", &productIDs)
+
+	respondRecommendations(c, productIDs)
+}
+
+// RefreshRecommendations rebuilds the co-purchase data now instead of
+// waiting for the periodic job.
+func RefreshRecommendations(c *gin.Context) {
+	refreshed, err := services.RefreshProductAssociations()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh recommendations"})
+		return
+	}
+	if !refreshed {
+		c.JSON(http.StatusConflict, gin.H{"error": "A refresh is already running"})
+		return
+	}
+
+	var associations int64
+	database.DB.Model(&models.ProductAssociation{}).Count(&associations)
+	c.JSON(http.StatusOK, gin.H{"message": "Recommendations refreshed", "associations": associations})
+}
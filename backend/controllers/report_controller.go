@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const reportDateLayout = "2006-01-02"
+
+// Orders that count as sales. Pending, held and failed orders are excluded.
+var revenueStatuses = []string{
+	models.OrderStatusPaid,
+	models.OrderStatusShipped,
+	models.OrderStatusDelivered,
+}
+
+type salesPeriodRow struct {
+	Period            time.Time `json:"period"`
+	Currency          string    `json:"currency"`
+	Revenue           int64     `json:"revenue"`
+	OrderCount        int64     `json:"order_count"`
+	AverageOrderValue int64     `json:"average_order_value"`
+}
+
+type salesCategoryRow struct {
+	Category          string `json:"category"`
+	Currency          string `json:"currency"`
+	Revenue           int64  `json:"revenue"`
+	OrderCount        int64  `json:"order_count"`
+	UnitsSold         int64  `json:"units_sold"`
+	AverageOrderValue int64  `json:"average_order_value"`
+}
+
+type salesProductRow struct {
+	ProductID         uint   `json:"product_id"`
+	Title             string `json:"title"`
+	Currency          string `json:"currency"`
+	Revenue           int64  `json:"revenue"`
+	OrderCount        int64  `json:"order_count"`
+	UnitsSold         int64  `json:"units_sold"`
+	AverageOrderValue int64  `json:"average_order_value"`
+}
+
+// reportRange reads ?from= and ?to= (inclusive dates), defaulting to the
+// last 30 days. The returned end is exclusive.
+func reportRange(c *gin.Context) (time.Time, time.Time, bool) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, to := today.AddDate(0, 0, -29), today
+
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(reportDateLayout, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be YYYY-MM-DD"})
+			return from, to, false
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(reportDateLayout, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be YYYY-MM-DD"})
+			return from, to, false
+		}
+		to = t
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return from, to, false
+	}
+
+	return from, to.AddDate(0, 0, 1), true
+}
+
+// salesOrders is the filtered order set every report aggregates over.
+func salesOrders(c *gin.Context, from, to time.Time) *gorm.DB {
+	query := database.DB.Table("orders").
+		Where("orders.status IN ?", revenueStatuses).
+		Where("orders.created_at >= ? AND orders.created_at < ?", from, to)
+
+	if currency := services.NormalizeCurrency(c.Query("currency")); currency != "" {
+		query = query.Where("orders.currency = ?", currency)
+	}
+	return query
+}
+
+func reportRangeJSON(from, to time.Time) gin.H {
+	return gin.H{
+		"from": from.Format(reportDateLayout),
+		"to":   to.AddDate(0, 0, -1).Format(reportDateLayout),
+	}
+}
+
+// GetSalesReport returns revenue, order count and average order value per
+// day, week or month.
+func GetSalesReport(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", "day")
+	if groupBy != "day" && groupBy != "week" && groupBy != "month" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be day, week or month"})
+		return
+	}
+
+	from, to, ok := reportRange(c)
+	if !ok {
+		return
+	}
+
+	var rows []salesPeriodRow
+	err := salesOrders(c, from, to).
+		Select(`date_trunc(?, orders.created_at) AS period,
+			orders.currency AS currency,
+			COALESCE(SUM(orders.total), 0)::bigint AS revenue,
+			COUNT(*) AS order_count,
+			COALESCE(ROUND(AVG(orders.total)), 0)::bigint AS average_order_value`, groupBy).
+		Group("period, orders.currency").
+		Order("period, orders.currency").
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"range": reportRangeJSON(from, to), "group_by": groupBy, "rows": rows})
+}
+
+func GetSalesByCategory(c *gin.Context) {
+	from, to, ok := reportRange(c)
+	if !ok {
+		return
+	}
+
+	var rows []salesCategoryRow
+	err := salesOrders(c, from, to).
+		Joins("JOIN products ON products.id = orders.product_id").
+		Select(`products.category AS category,
+			orders.currency AS currency,
+			COALESCE(SUM(orders.total), 0)::bigint AS revenue,
+			COUNT(*) AS order_count,
+			COALESCE(SUM(orders.quantity), 0)::bigint AS units_sold,
+			COALESCE(ROUND(AVG(orders.total)), 0)::bigint AS average_order_value`).
+		Group("products.category, orders.currency").
+		Order("revenue DESC").
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"range": reportRangeJSON(from, to), "rows": rows})
+}
+
+func GetSalesByProduct(c *gin.Context) {
+	from, to, ok := reportRange(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	var rows []salesProductRow
+	err = salesOrders(c, from, to).
+		Joins("JOIN products ON products.id = orders.product_id").
+		Select(`orders.product_id AS product_id,
+			products.title AS title,
+			orders.currency AS currency,
+			COALESCE(SUM(orders.total), 0)::bigint AS revenue,
+			COUNT(*) AS order_count,
+			COALESCE(SUM(orders.quantity), 0)::bigint AS units_sold,
+			COALESCE(ROUND(AVG(orders.total)), 0)::bigint AS average_order_value`).
+		Group("orders.product_id, products.title, orders.currency").
+		Order("revenue DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"range": reportRangeJSON(from, to), "rows": rows})
+}
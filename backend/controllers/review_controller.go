@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultReviewLimit = 10
+	maxReviewLimit     = 50
+)
+
+var reviewSorts = map[string]string{
+	"newest":  "reviews.id DESC",
+	"oldest":  "reviews.id ASC",
+	"highest": "reviews.rating DESC, reviews.id DESC",
+	"lowest":  "reviews.rating ASC, reviews.id DESC",
+	"helpful": "reviews.helpful_count DESC, reviews.id DESC",
+}
+
+type reviewPayload struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Title  string `json:"title" binding:"max=200"`
+	Body   string `json:"body" binding:"max=5000"`
+}
+
+// reviewsWithAuthor selects reviews along with the reviewer's display name.
+func reviewsWithAuthor(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Review{}).
+		Select("reviews.*, users.name AS author_name").
+		Joins("LEFT JOIN users ON users.id = reviews.user_id")
+}
+
+func isAdmin(userID uint) bool {
+	var user models.User
+	if err := database.DB.Select("role").First(&user, userID).Error; err != nil {
+		return false
+	}
+	return user.Role == "admin"
+}
+
+// findProductReview loads a review of the product named in the URL.
+func findProductReview(c *gin.Context) (models.Review, bool) {
+	var review models.Review
+	err := reviewsWithAuthor(database.DB).
+		Where("reviews.id = ? AND reviews.product_id IN (?)", c.Param("reviewId"), productIDQuery(c.Param("id"))).
+		First(&review).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return review, false
+	}
+	return review, true
+}
+
+func GetProductReviews(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product, services.PublishedProducts) {
+		return
+	}
+
+	sort := c.DefaultQuery("sort", "newest")
+	order, ok := reviewSorts[sort]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be one of newest, oldest, highest, lowest, helpful"})
+		return
+	}
+	page, limit, err := parsePage(c, defaultReviewLimit, maxReviewLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var reviews []models.Review
+	err = reviewsWithAuthor(database.DB).
+		Where("reviews.product_id = ? AND reviews.status = ?", product.ID, models.ReviewStatusApproved).
+		Order(order).
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&reviews).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reviews"})
+		return
+	}
+
+	distribution, err := services.RatingDistribution(database.DB, product.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reviews"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  reviews,
+		"total": product.ReviewCount,
+		"page":  page,
+		"limit": limit,
+		"sort":  sort,
+		"rating": gin.H{
+			"average":      product.RatingAverage,
+			"count":        product.ReviewCount,
+			"distribution": distribution,
+		},
+	})
+}
+
+// CreateProductReview lets a customer with a delivered order for the product
+// review it once. The review is screened and may wait for moderation.
+func CreateProductReview(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product, services.PublishedProducts) {
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
+	}
+
+	var body reviewPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orderID, err := services.DeliveredOrderID(database.DB, userID, product.ID)
+	if errors.Is(err, services.ErrNotVerifiedBuyer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check orders"})
+		return
+	}
+
+	var existing int64
+	database.DB.Model(&models.Review{}).Where("product_id = ? AND user_id = ?", product.ID, userID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already reviewed this product"})
+		return
+	}
+
+	review := models.Review{
+		ProductID: product.ID,
+		UserID:    userID,
+		OrderID:   orderID,
+		Rating:    body.Rating,
+		Title:     strings.TrimSpace(body.Title),
+		Body:      strings.TrimSpace(body.Body),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.ScreenReview(tx, &review); err != nil {
+			return err
+		}
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		return services.RefreshProductRating(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+func UpdateProductReview(c *gin.Context) {
+	review, ok := findProductReview(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	if review.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only edit your own review"})
+		return
+	}
+
+	var body reviewPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review.Rating = body.Rating
+	review.Title = strings.TrimSpace(body.Title)
+	review.Body = strings.TrimSpace(body.Body)
+
+	// Edited reviews go through screening again.
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.ScreenReview(tx, &review); err != nil {
+			return err
+		}
+		err := tx.Model(&review).
+			Select("Rating", "Title", "Body", "UpdatedAt", "Status", "Flags", "ModerationNote", "ModeratedBy", "ModeratedAt").
+			Updates(&review).Error
+		if err != nil {
+			return err
+		}
+		return services.RefreshProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// DeleteProductReview removes a review. Customers can delete their own;
+// admins can delete any.
+func DeleteProductReview(c *gin.Context) {
+	review, ok := findProductReview(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	if review.UserID != userID && !isAdmin(userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own review"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("review_id = ?", review.ID).Delete(&models.ReviewVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Review{}, review.ID).Error; err != nil {
+			return err
+		}
+		return services.RefreshProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review deleted"})
+}
+
+// VoteReviewHelpful marks a review as helpful. Voting twice has no effect.
+func VoteReviewHelpful(c *gin.Context) {
+	review, ok := findProductReview(c)
+	if !ok {
+		return
+	}
+
+	if review.Status != models.ReviewStatusApproved {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	if review.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot vote on your own review"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.ReviewVote{ReviewID: review.ID, UserID: userID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.Review{}).Where("id = ?", review.ID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save vote"})
+		return
+	}
+
+	reviewHelpfulCount(c, review.ID)
+}
+
+func UnvoteReviewHelpful(c *gin.Context) {
+	review, ok := findProductReview(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("review_id = ? AND user_id = ?", review.ID, userID).Delete(&models.ReviewVote{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.Review{}).Where("id = ?", review.ID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count - 1")).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove vote"})
+		return
+	}
+
+	reviewHelpfulCount(c, review.ID)
+}
+
+func reviewHelpfulCount(c *gin.Context, reviewID uint) {
+	var count int
+	database.DB.Model(&models.Review{}).Where("id = ?", reviewID).Select("helpful_count").Scan(&count)
+	c.JSON(http.StatusOK, gin.H{"review_id": reviewID, "helpful_count": count})
+}
+
+// GetMyReviews lists the current user's reviews in every state, so authors
+// can see what is still waiting for moderation.
+func GetMyReviews(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var reviews []models.Review
+	reviewsWithAuthor(database.DB).Where("reviews.user_id = ?", userID).Order("reviews.id DESC").Find(&reviews)
+	c.JSON(http.StatusOK, reviews)
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// Minimum trigram word similarity for a title to match a misspelled query.
+const searchSimilarity = 0.4
+
+// prefixTSQuery turns free text into a tsquery that matches every word as a
+// prefix, e.g. "mac pro" becomes "mac:* & pro:*". Punctuation is dropped so
+// user input can never produce tsquery syntax errors.
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// SearchProducts ranks products by full-text relevance across title,
+// category and description, falling back to trigram similarity on the title
+// so small typos still match.
+func SearchProducts(c *gin.Context) {
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	// Results are ranked by relevance and paged by page number only.
+	if c.Query("sort") != "" || c.Query("cursor") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search does not support sort or cursor; use page"})
+		return
+	}
+	q, err := parseProductQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tsquery := prefixTSQuery(text)
+	matched := database.DB.Model(&models.Product{}).Scopes(services.PublishedProducts)
+	if tsquery != "" {
+		matched = matched.Where(
+			"(products.search_vector @@ to_tsquery('english', ?) OR word_similarity(?, products.title) >= ?)",
+			tsquery, text, searchSimilarity,
+		)
+	} else {
+		matched = matched.Where("word_similarity(?, products.title) >= ?", text, searchSimilarity)
+	}
+
+	matched, err = parseProductFilters(c, matched)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var total int64
+	if err := matched.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search products"})
+		return
+	}
+
+	ranked := matched.Session(&gorm.Session{})
+	if tsquery != "" {
+		ranked = ranked.Select(
+			"products.*, ts_rank_cd(products.search_vector, to_tsquery('english', ?)) + word_similarity(?, products.title) AS rank",
+			tsquery, text,
+		)
+	} else {
+		ranked = ranked.Select("products.*, word_similarity(?, products.title) AS rank", text)
+	}
+
+	var products []models.Product
+	err = ranked.
+		Order("rank DESC").
+		Order("products.id DESC").
+		Offset((q.Page - 1) * q.Limit).
+		Limit(q.Limit).
+		Find(&products).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search products"})
+		return
+	}
+
+	if !presentProducts(c, products) {
+		return
+	}
+
+	response := gin.H{"data": products, "total": total, "page": q.Page, "limit": q.Limit, "query": text}
+	if !addFacets(c, response, matched) {
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"time"
+)
+
+// saveUpload checks an uploaded image's sniffed type and size, stores a
+// metadata-free copy plus its renditions under uploads/ and returns the key
+// to keep on the record. Keys are derived from the file's content; the
+// client's filename is ignored.
+func saveUpload(c *gin.Context, file *multipart.FileHeader) (string, error) {
+	maxSize := services.MaxUploadSize()
+	if file.Size > maxSize {
+		return "", fmt.Errorf("%w: uploads must be %dMB or smaller", services.ErrFileTooLarge, maxSize>>20)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	// Read one byte past the limit so oversized files are still caught when
+	// the declared size is wrong.
+	data, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	contentType, err := services.SniffUpload(data)
+	if err != nil {
+		return "", err
+	}
+
+	processed, err := services.ProcessImage(data)
+	if err != nil {
+		return "", err
+	}
+
+	path, renditions, err := services.WriteProcessedImage(c.Request.Context(), processed, "uploads", services.ContentName(data))
+	if err != nil {
+		return "", err
+	}
+
+	// Uploading the same file again restarts its cleanup grace period.
+	upload := models.Upload{Key: path, ContentType: contentType, Size: int64(len(data)), UploadedAt: time.Now()}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"content_type", "size", "uploaded_at"}),
+		}).Create(&upload).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("source_path = ?", path).Delete(&models.ImageRendition{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&renditions).Error
+	})
+	return path, err
+}
+
+// uploadError reports a failed upload, telling the client why when the file
+// itself was the problem.
+func uploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrUnsupportedFileType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrInvalidImage), errors.Is(err, services.ErrImageTooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save upload"})
+}
+
+// fileURL resolves a stored key to a URL clients can fetch, or "" when the
+// storage backend cannot produce one.
+func fileURL(c *gin.Context, key string) string {
+	if key == "" {
+		return ""
+	}
+	url, err := services.Files.URL(c.Request.Context(), key)
+	if err != nil {
+		log.Printf("storage: no URL for %s: %v", key, err)
+		return ""
+	}
+	return url
+}
+
+// imageSizes loads the renditions of the given originals, keyed by the
+// original's path, with URLs for each size and format.
+func imageSizes(c *gin.Context, paths []string) map[string]models.ImageSizes {
+	sizes := map[string]models.ImageSizes{}
+	if len(paths) == 0 {
+		return sizes
+	}
+
+	var renditions []models.ImageRendition
+	database.DB.Where("source_path IN ?", paths).Find(&renditions)
+	for _, r := range renditions {
+		if sizes[r.SourcePath] == nil {
+			sizes[r.SourcePath] = models.ImageSizes{}
+		}
+		if sizes[r.SourcePath][r.Size] == nil {
+			sizes[r.SourcePath][r.Size] = map[string]string{}
+		}
+		sizes[r.SourcePath][r.Size][r.Format] = fileURL(c, r.Path)
+	}
+	return sizes
+}
+
+func attachImageURLs(c *gin.Context, products []models.Product) {
+	var paths []string
+	for _, product := range products {
+		if product.Image != "" {
+			paths = append(paths, product.Image)
+		}
+		for _, image := range product.Images {
+			paths = append(paths, image.Path)
+		}
+	}
+
+	sizes := imageSizes(c, paths)
+	for i := range products {
+		product := &products[i]
+		product.ImageURL = fileURL(c, product.Image)
+		product.ImageSizes = sizes[product.Image]
+		for j := range product.Images {
+			product.Images[j].URL = fileURL(c, product.Images[j].Path)
+			product.Images[j].Sizes = sizes[product.Images[j].Path]
+		}
+	}
+}
+
+func attachGalleryURLs(c *gin.Context, images []models.ProductImage) []models.ProductImage {
+	paths := make([]string, len(images))
+	for i, image := range images {
+		paths[i] = image.Path
+	}
+
+	sizes := imageSizes(c, paths)
+	for i := range images {
+		images[i].URL = fileURL(c, images[i].Path)
+		images[i].Sizes = sizes[images[i].Path]
+	}
+	return images
+}
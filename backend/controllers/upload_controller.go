@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"ecommerce/backend/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// uploadGrace reads an optional ?grace_hours= override of the cleanup grace
+// period.
+func uploadGrace(c *gin.Context) (time.Duration, bool) {
+	value := c.Query("grace_hours")
+	if value == "" {
+		return services.UploadGracePeriod(), true
+	}
+	hours, err := strconv.Atoi(value)
+	if err != nil || hours < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "grace_hours must be a non-negative integer"})
+		return 0, false
+	}
+	return time.Duration(hours) * time.Hour, true
+}
+
+// GetOrphanedUploads is a dry run of the cleanup: it lists the uploads that
+// would be removed without touching them.
+func GetOrphanedUploads(c *gin.Context) {
+	grace, ok := uploadGrace(c)
+	if !ok {
+		return
+	}
+
+	report, err := services.CleanupOrphanedUploads(c.Request.Context(), grace, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find orphaned uploads"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func CleanupUploads(c *gin.Context) {
+	grace, ok := uploadGrace(c)
+	if !ok {
+		return
+	}
+
+	report, err := services.CleanupOrphanedUploads(c.Request.Context(), grace, c.Query("dry_run") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clean up uploads"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -17,7 +17,7 @@ func GetUser(c *gin.Context) {
 	id := c.Param("id")
 	var user models.User
 
-	if err := database.DB.First(&user, id).Error; err != nil {
+	if err := database.DB.Where("id = ?", id).First(&user).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
@@ -41,7 +41,7 @@ func UpdateUser(c *gin.Context) {
 	id := c.Param("id")
 	var user models.User
 
-	if err := database.DB.First(&user, id).Error; err != nil {
+	if err := database.DB.Where("id = ?", id).First(&user).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
@@ -54,7 +54,7 @@ func UpdateUser(c *gin.Context) {
 
 func DeleteUser(c *gin.Context) {
 	id := c.Param("id")
-	database.DB.Delete(&models.User{}, id)
+	database.DB.Where("id = ?", id).Delete(&models.User{})
 
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
 }
@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+type productOptionPayload struct {
+	Name     string   `json:"name" binding:"required"`
+	Position int      `json:"position"`
+	Values   []string `json:"values" binding:"required,min=1"`
+}
+
+type productVariantPayload struct {
+	SKU            string `json:"sku" binding:"required"`
+	Price          *int64 `json:"price" binding:"omitempty,min=0"`
+	Stock          *int   `json:"stock" binding:"omitempty,min=0"`
+	Image          string `json:"image"`
+	OptionValueIDs []uint `json:"option_value_ids"`
+}
+
+func preloadVariants(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position, id") }).
+		Preload("Options.Values", func(db *gorm.DB) *gorm.DB { return db.Order("position, id") }).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Variants.Options")
+}
+
+// resolveVariantOptions loads the chosen option values and checks that they
+// belong to the product and pick at most one value per option.
+func resolveVariantOptions(c *gin.Context, productID uint, ids []uint) ([]models.ProductOptionValue, bool) {
+	if len(ids) == 0 {
+		return nil, true
+	}
+
+	var values []models.ProductOptionValue
+	database.DB.
+		Joins("JOIN product_options ON product_options.id = product_option_values.option_id").
+		Where("product_option_values.id IN ? AND product_options.product_id = ?", ids, productID).
+		Find(&values)
+	if len(values) != len(ids) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Option values must belong to this product"})
+		return nil, false
+	}
+
+	seen := map[uint]bool{}
+	for _, value := range values {
+		if seen[value.OptionID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Pick at most one value per option"})
+			return nil, false
+		}
+		seen[value.OptionID] = true
+	}
+	return values, true
+}
+
+// skuTaken reports whether any product, or a variant other than exceptID,
+// has the SKU.
+func skuTaken(sku string, exceptID uint) bool {
+	taken, _ := services.SKUTaken(database.DB, sku, 0, exceptID)
+	return taken
+}
+
+func GetProductVariants(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product, preloadVariants, services.PublishedProducts) {
+		return
+	}
+
+	products := []models.Product{product}
+	if !presentProducts(c, products) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"options": products[0].Options, "variants": products[0].Variants})
+}
+
+func CreateProductOption(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product) {
+		return
+	}
+
+	var body productOptionPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	option := models.ProductOption{ProductID: product.ID, Name: strings.TrimSpace(body.Name), Position: body.Position}
+	for i, value := range body.Values {
+		option.Values = append(option.Values, models.ProductOptionValue{Value: strings.TrimSpace(value), Position: i})
+	}
+
+	if err := database.DB.Create(&option).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create option"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, option)
+}
+
+func DeleteProductOption(c *gin.Context) {
+	var option models.ProductOption
+
+	err := database.DB.Where("id = ? AND product_id IN (?)", c.Param("optionId"), productIDQuery(c.Param("id"))).First(&option).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Option not found"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		valueIDs := tx.Model(&models.ProductOptionValue{}).Select("id").Where("option_id = ?", option.ID)
+		if err := tx.Exec("DELETE FROM product_variant_options WHERE product_option_value_id IN (?)", valueIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("option_id = ?", option.ID).Delete(&models.ProductOptionValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&option).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete option"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Option deleted"})
+}
+
+func CreateProductVariant(c *gin.Context) {
+	var product models.Product
+	if !findProduct(c, &product) {
+		return
+	}
+
+	var body productVariantPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant := models.ProductVariant{
+		ProductID: product.ID,
+		SKU:       strings.TrimSpace(body.SKU),
+		Price:     body.Price,
+		Stock:     body.Stock,
+		Image:     body.Image,
+	}
+	if skuTaken(variant.SKU, 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "SKU already in use"})
+		return
+	}
+
+	values, ok := resolveVariantOptions(c, product.ID, body.OptionValueIDs)
+	if !ok {
+		return
+	}
+	variant.Options = values
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if variant.Price != nil {
+			if err := services.CheckPriceOverridable(tx, product.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Options.*").Create(&variant).Error
+	})
+	if errors.Is(err, services.ErrPriceScheduled) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Variants of products with price schedules cannot have their own price"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create variant"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, variant)
+}
+
+func UpdateProductVariant(c *gin.Context) {
+	var variant models.ProductVariant
+
+	err := database.DB.Where("id = ? AND product_id IN (?)", c.Param("variantId"), productIDQuery(c.Param("id"))).First(&variant).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+		return
+	}
+
+	var body productVariantPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant.SKU = strings.TrimSpace(body.SKU)
+	variant.Price = body.Price
+	variant.Stock = body.Stock
+	variant.Image = body.Image
+	if skuTaken(variant.SKU, variant.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "SKU already in use"})
+		return
+	}
+
+	values, ok := resolveVariantOptions(c, variant.ProductID, body.OptionValueIDs)
+	if !ok {
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if variant.Price != nil {
+			if err := services.CheckPriceOverridable(tx, variant.ProductID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("Options").Save(&variant).Error; err != nil {
+			return err
+		}
+		return tx.Model(&variant).Omit("Options.*").Association("Options").Replace(values)
+	})
+	if errors.Is(err, services.ErrPriceScheduled) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Variants of products with price schedules cannot have their own price"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save variant"})
+		return
+	}
+
+	variant.Options = values
+	c.JSON(http.StatusOK, variant)
+}
+
+func DeleteProductVariant(c *gin.Context) {
+	var variant models.ProductVariant
+
+	err := database.DB.Where("id = ? AND product_id IN (?)", c.Param("variantId"), productIDQuery(c.Param("id"))).First(&variant).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&variant).Association("Options").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&variant).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete variant"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Variant deleted"})
+}
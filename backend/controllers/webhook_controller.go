@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type webhookPayload struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events" binding:"required,min=1"`
+	Active *bool    `json:"active"`
+}
+
+func validateWebhook(c *gin.Context, body webhookPayload) bool {
+	if u, err := url.Parse(body.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook URL must be http or https"})
+		return false
+	}
+	for _, event := range body.Events {
+		if !services.ValidWebhookEvent(event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event: " + event})
+			return false
+		}
+	}
+	return true
+}
+
+// maskWebhookSecret hides all but the end of a secret. Secrets are shown in
+// full only when they are created or rotated.
+func maskWebhookSecret(hook *models.Webhook) {
+	visible := ""
+	if len(hook.Secret) > 12 {
+		visible = hook.Secret[len(hook.Secret)-4:]
+	}
+	hook.Secret = strings.Repeat("*", 8) + visible
+}
+
+func GetWebhookEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, services.WebhookEvents)
+}
+
+func GetWebhooks(c *gin.Context) {
+	var hooks []models.Webhook
+	database.DB.Order("id").Find(&hooks)
+	for i := range hooks {
+		maskWebhookSecret(&hooks[i])
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+func GetWebhook(c *gin.Context) {
+	id := c.Param("id")
+	var hook models.Webhook
+
+	if err := database.DB.Where("id = ?", id).First(&hook).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	maskWebhookSecret(&hook)
+	c.JSON(http.StatusOK, hook)
+}
+
+func CreateWebhook(c *gin.Context) {
+	var body webhookPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validateWebhook(c, body) {
+		return
+	}
+
+	hook := models.Webhook{URL: body.URL, Secret: body.Secret, Events: body.Events, Active: true}
+	if body.Active != nil {
+		hook.Active = *body.Active
+	}
+	if hook.Secret == "" {
+		secret, err := services.NewWebhookSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+			return
+		}
+		hook.Secret = secret
+	}
+
+	if err := database.DB.Create(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+func UpdateWebhook(c *gin.Context) {
+	id := c.Param("id")
+	var hook models.Webhook
+
+	if err := database.DB.Where("id = ?", id).First(&hook).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	var body webhookPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validateWebhook(c, body) {
+		return
+	}
+
+	hook.URL = body.URL
+	hook.Events = body.Events
+	if body.Secret != "" {
+		hook.Secret = body.Secret
+	}
+	if body.Active != nil {
+		hook.Active = *body.Active
+	}
+
+	if err := database.DB.Save(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save webhook"})
+		return
+	}
+
+	if body.Secret == "" {
+		maskWebhookSecret(&hook)
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// RotateWebhookSecret replaces a webhook's signing secret with a new random
+// one and returns it; this is the only time it is shown.
+func RotateWebhookSecret(c *gin.Context) {
+	id := c.Param("id")
+	var hook models.Webhook
+
+	if err := database.DB.Where("id = ?", id).First(&hook).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	secret, err := services.NewWebhookSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	hook.Secret = secret
+	if err := database.DB.Model(&hook).Update("secret", hook.Secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+func DeleteWebhook(c *gin.Context) {
+	id := c.Param("id")
+	database.DB.Where("id = ?", id).Delete(&models.Webhook{})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+func GetWebhookDeliveries(c *gin.Context) {
+	id := c.Param("id")
+	var deliveries []models.WebhookDelivery
+
+	query := database.DB.Where("webhook_id = ?", id)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	query.Order("id DESC").Limit(100).Find(&deliveries)
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func RedeliverWebhook(c *gin.Context) {
+	var delivery models.WebhookDelivery
+
+	err := database.DB.
+		Where("id = ? AND webhook_id = ?", c.Param("deliveryId"), c.Param("id")).
+		First(&delivery).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	redelivery, err := services.Redeliver(delivery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue redelivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, redelivery)
+}
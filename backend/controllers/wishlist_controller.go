@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/middleware"
+	"ecommerce/backend/models"
+	"ecommerce/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strings"
+)
+
+type wishlistPayload struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type wishlistItemPayload struct {
+	ProductID uint  `json:"product_id" binding:"required"`
+	VariantID *uint `json:"variant_id"`
+}
+
+type moveToCartPayload struct {
+	Quantity int `json:"quantity"`
+}
+
+// findWishlist loads one of the current user's wishlists by ID, or their
+// default list when the ID is "default".
+func findWishlist(c *gin.Context) (models.Wishlist, bool) {
+	userID, _ := middleware.GetUserID(c)
+
+	if c.Param("id") == "default" {
+		wishlist, err := services.DefaultWishlist(database.DB, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load wishlist"})
+			return wishlist, false
+		}
+		return wishlist, true
+	}
+
+	var wishlist models.Wishlist
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&wishlist).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist not found"})
+		return wishlist, false
+	}
+	return wishlist, true
+}
+
+// withItems loads a wishlist's items, newest first, with their products.
+// Items whose product has been deleted or unpublished are left out.
+func withItems(c *gin.Context, wishlist *models.Wishlist) bool {
+	var items []models.WishlistItem
+	database.DB.Where("wishlist_id = ?", wishlist.ID).Order("id DESC").Find(&items)
+
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	products, ok := presentItemProducts(c, ids)
+	if !ok {
+		return false
+	}
+
+	wishlist.Items = []models.WishlistItem{}
+	for _, item := range items {
+		if product, ok := products[item.ProductID]; ok {
+			item.Product = product
+			wishlist.Items = append(wishlist.Items, item)
+		}
+	}
+	wishlist.ItemCount = len(wishlist.Items)
+	return true
+}
+
+func GetWishlists(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	if _, err := services.DefaultWishlist(database.DB, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load wishlists"})
+		return
+	}
+
+	var wishlists []models.Wishlist
+	database.DB.Model(&models.Wishlist{}).
+		Select("wishlists.*, (SELECT COUNT(*) FROM wishlist_items WHERE wishlist_items.wishlist_id = wishlists.id) AS item_count").
+		Where("user_id = ?", userID).
+		Order("is_default DESC, id").
+		Find(&wishlists)
+
+	c.JSON(http.StatusOK, wishlists)
+}
+
+func CreateWishlist(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var body wishlistPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlist := models.Wishlist{UserID: userID, Name: strings.TrimSpace(body.Name)}
+	if err := database.DB.Create(&wishlist).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, wishlist)
+}
+
+func GetWishlist(c *gin.Context) {
+	wishlist, ok := findWishlist(c)
+	if !ok || !withItems(c, &wishlist) {
+		return
+	}
+
+	c.JSON(http.StatusOK, wishlist)
+}
+
+func UpdateWishlist(c *gin.Context) {
+	wishlist, ok := findWishlist(c)
+	if !ok {
+		return
+	}
+
+	var body wishlistPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlist.Name = strings.TrimSpace(body.Name)
+	if err := database.DB.Model(&wishlist).Update("name", wishlist.Name).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, wishlist)
+}
+
+func DeleteWishlist(c *gin.Context) {
+	wishlist, ok := findWishlist(c)
+	if !ok {
+		return
+	}
+	if wishlist.IsDefault {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The default wishlist cannot be deleted"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id = ?", wishlist.ID).Delete(&models.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&wishlist).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wishlist deleted"})
+}
+
+// AddWishlistItem adds a product to a wishlist. Adding one that is already
+// there returns the existing item.
+func AddWishlistItem(c *gin.Context) {
+	wishlist, ok := findWishlist(c)
+	if !ok {
+		return
+	}
+
+	var body wishlistItemPayload
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Wishlists may hold a product before a variant is picked.
+	if err := services.CheckVariant(database.DB, body.ProductID, body.VariantID); err != nil && err != services.ErrVariantRequired {
+		cartError(c, err)
+		return
+	}
+
+	// The unique index on the line turns a concurrent duplicate add into a
+	// no-op; the existing row is returned instead.
+	item := models.WishlistItem{WishlistID: wishlist.ID, ProductID: body.ProductID, VariantID: body.VariantID}
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&item)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add to wishlist"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		query := database.DB.Where("wishlist_id = ? AND product_id = ?", wishlist.ID, body.ProductID)
+		if body.VariantID != nil {
+			query = query.Where("variant_id = ?", *body.VariantID)
+		} else {
+			query = query.Where("variant_id IS NULL")
+		}
+		if err := query.First(&item).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add to wishlist"})
+			return
+		}
+		c.JSON(http.StatusOK, item)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func DeleteWishlistItem(c *gin.Context) {
+	wishlist, ok := findWishlist(c)
+	if !ok {
+		return
+	}
+
+	database.DB.Where("id = ? AND wishlist_id = ?", c.Param("itemId"), wishlist.ID).Delete(&models.WishlistItem{})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from wishlist"})
+}
+
+// MoveWishlistItemToCart adds the item to the cart and removes it from the
+// wishlist in one step.
+func MoveWishlistItemToCart(c *gin.Context) {
+	wishlist, ok := findWishlist(c)
+	if !ok {
+		return
+	}
+
+	var item models.WishlistItem
+	if err := database.DB.Where("id = ? AND wishlist_id = ?", c.Param("itemId"), wishlist.ID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist item not found"})
+		return
+	}
+
+	var body moveToCartPayload
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if body.Quantity == 0 {
+		body.Quantity = 1
+	}
+	if body.Quantity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be positive"})
+		return
+	}
+
+	var cartItem models.CartItem
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		cartItem, err = services.AddToCart(tx, wishlist.UserID, item.ProductID, item.VariantID, body.Quantity)
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&item).Error
+	})
+	if err != nil {
+		cartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cartItem)
+}
+
+// ShareWishlist gives the wishlist a public link. Sharing again replaces the
+// token, which revokes the old link.
+func ShareWishlist(c *gin.Context) {
+	wishlist, ok := findWishlist(c)
+	if !ok {
+		return
+	}
+
+	token, err := services.NewShareToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share wishlist"})
+		return
+	}
+
+	wishlist.ShareToken = &token
+	if err := database.DB.Model(&wishlist).Update("share_token", token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"share_token": token, "share_path": "/api/wishlists/shared/" + token})
+}
+
+func UnshareWishlist(c *gin.Context) {
+	wishlist, ok := findWishlist(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Model(&wishlist).Update("share_token", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unshare wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wishlist is no longer shared"})
+}
+
+// GetSharedWishlist shows a shared wishlist to anyone with its token. Only
+// the list's name and products are exposed.
+func GetSharedWishlist(c *gin.Context) {
+	var wishlist models.Wishlist
+
+	token := c.Param("token")
+	if err := database.DB.Where("share_token = ?", token).First(&wishlist).Error; err != nil || token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist not found"})
+		return
+	}
+	if !withItems(c, &wishlist) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"name": wishlist.Name, "items": wishlist.Items, "item_count": wishlist.ItemCount})
+}
@@ -3,20 +3,331 @@ package database
 import (
 	"fmt"
 	"ecommerce/backend/models"
+	"ecommerce/backend/utils"
+	"strings"
+
+	"gorm.io/gorm"
 )
 
 func Migrate() {
 	fmt.Println("Running migrations...")
 
+	// One-shot conversions run first, each in the transaction that adds the
+	// column marking it done, so a failure in any later step cannot skip them.
+	if err := migratePrices(); err != nil {
+		fmt.Println("Price migration error:", err)
+		return
+	}
+
+	if err := migrateReviewStatus(); err != nil {
+		fmt.Println("Review migration error:", err)
+		return
+	}
+
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.Product{},
 		&models.Order{},
+		&models.ExchangeRate{},
+		&models.ProductPrice{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.OutboxEvent{},
+		&models.FraudRule{},
+		&models.FraudCheck{},
+		&models.FraudHit{},
+		&models.Category{},
+		&models.ProductOption{},
+		&models.ProductOptionValue{},
+		&models.ProductVariant{},
+		&models.ProductImage{},
+		&models.ImageRendition{},
+		&models.Upload{},
+		&models.Review{},
+		&models.ReviewVote{},
+		&models.BannedWord{},
+		&models.Notification{},
+		&models.ProductQuestion{},
+		&models.ProductAnswer{},
+		&models.AnswerVote{},
+		&models.Wishlist{},
+		&models.WishlistItem{},
+		&models.CartItem{},
+		&models.Attribute{},
+		&models.ProductAssociation{},
+		&models.PriceSchedule{},
+		&models.PriceChange{},
+		&models.ProductSlugRedirect{},
 	)
 
 	if err != nil {
 		fmt.Println("Migration error:", err)
-	} else {
-		fmt.Println("Migration done.")
+		return
+	}
+
+	if err := migrateSearch(); err != nil {
+		fmt.Println("Search migration error:", err)
+		return
+	}
+
+	if err := migrateAttributes(); err != nil {
+		fmt.Println("Attribute migration error:", err)
+		return
+	}
+
+	if err := migrateListIndexes(); err != nil {
+		fmt.Println("Wishlist and cart migration error:", err)
+		return
+	}
+
+	if err := migrateCategories(); err != nil {
+		fmt.Println("Category migration error:", err)
+		return
+	}
+
+	if err := migrateProductSlugs(); err != nil {
+		fmt.Println("Product slug migration error:", err)
+		return
+	}
+
+	if err := migrateProductImages(); err != nil {
+		fmt.Println("Product image migration error:", err)
+		return
 	}
+
+	if err := migrateUploads(); err != nil {
+		fmt.Println("Upload migration error:", err)
+		return
+	}
+
+	if err := migratePriceHistory(); err != nil {
+		fmt.Println("Price history migration error:", err)
+		return
+	}
+
+	fmt.Println("Migration done.")
+}
+
+// migratePrices converts prices to minor units. They used to be whole units
+// with no currency, so the conversion runs when the currency column is added.
+func migratePrices() error {
+	if !DB.Migrator().HasTable(&models.Product{}) || DB.Migrator().HasColumn(&models.Product{}, "Currency") {
+		return nil
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&models.Product{}, "Currency"); err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE products SET price = price * 100").Error
+	})
+}
+
+// migrateReviewStatus approves reviews written before moderation existed,
+// which were already public, when the status column is added.
+func migrateReviewStatus() error {
+	if !DB.Migrator().HasTable(&models.Review{}) || DB.Migrator().HasColumn(&models.Review{}, "Status") {
+		return nil
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&models.Review{}, "Status"); err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE reviews SET status = ?", models.ReviewStatusApproved).Error
+	})
+}
+
+// migrateSearch adds the product search column and indexes. The tsvector is
+// a generated column, so Postgres keeps it current on every insert and update.
+func migrateSearch() error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('english', coalesce(category, '')), 'B') ||
+				setweight(to_tsvector('english', coalesce(description, '')), 'C')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_products_title_trgm ON products USING GIN (title gin_trgm_ops)`,
+	}
+
+	for _, statement := range statements {
+		if err := DB.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateAttributes indexes product attributes for containment filters such
+// as attributes @> '{"brand": "Acme"}'.
+func migrateAttributes() error {
+	return DB.Exec(`CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes jsonb_path_ops)`).Error
+}
+
+// migrateListIndexes adds the unique indexes that keep concurrent requests
+// from creating a second default wishlist or duplicate wishlist and cart
+// lines. Duplicates from before are merged first: extra default lists become
+// ordinary ones, and repeated cart lines are combined into the oldest. Lines
+// without a variant are indexed as variant 0 so they are unique too; NULLS NOT
+// DISTINCT would need PostgreSQL 15, so the indexes that used it are dropped.
+func migrateListIndexes() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			`UPDATE wishlists SET is_default = false
+			WHERE is_default AND id NOT IN (SELECT MIN(id) FROM wishlists WHERE is_default GROUP BY user_id)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_wishlists_user_default ON wishlists (user_id) WHERE is_default`,
+
+			`DELETE FROM wishlist_items a USING wishlist_items b
+			WHERE a.wishlist_id = b.wishlist_id AND a.product_id = b.product_id
+				AND a.variant_id IS NOT DISTINCT FROM b.variant_id AND a.id > b.id`,
+			`DROP INDEX IF EXISTS idx_wishlist_items_line`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_wishlist_items_unique_line
+			ON wishlist_items (wishlist_id, product_id, COALESCE(variant_id, 0))`,
+
+			`UPDATE cart_items SET quantity = merged.quantity
+			FROM (
+				SELECT MIN(id) AS id, SUM(quantity) AS quantity FROM cart_items
+				GROUP BY user_id, product_id, variant_id HAVING COUNT(*) > 1
+			) merged
+			WHERE cart_items.id = merged.id`,
+			`DELETE FROM cart_items a USING cart_items b
+			WHERE a.user_id = b.user_id AND a.product_id = b.product_id
+				AND a.variant_id IS NOT DISTINCT FROM b.variant_id AND a.id > b.id`,
+			`DROP INDEX IF EXISTS idx_cart_items_line`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_items_unique_line
+			ON cart_items (user_id, product_id, COALESCE(variant_id, 0))`,
+		}
+
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateCategories moves free-text product categories into the categories
+// table. Strings with the same slug, such as "Smartphones" and "smartphones",
+// become one category named after the first spelling found. Slugs made only
+// of digits, which read as IDs, get the prefix new ones get.
+func migrateCategories() error {
+	err := DB.Exec(`
+		UPDATE categories SET slug = 'category-' || slug
+		WHERE slug ~ '^[0-9]+$'
+		AND NOT EXISTS (SELECT 1 FROM categories c WHERE c.slug = 'category-' || categories.slug)`).Error
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	err = DB.Model(&models.Product{}).
+		Where("category_id IS NULL AND category <> ''").
+		Distinct().
+		Order("category").
+		Pluck("category", &names).Error
+	if err != nil {
+		return err
+	}
+
+	spellings := map[string][]string{}
+	var slugs []string
+	for _, name := range names {
+		if utils.Slugify(name) == "" {
+			continue
+		}
+		slug := utils.ResourceSlug(name, "category")
+		if _, seen := spellings[slug]; !seen {
+			slugs = append(slugs, slug)
+		}
+		spellings[slug] = append(spellings[slug], name)
+	}
+
+	for _, slug := range slugs {
+		category := models.Category{Name: strings.TrimSpace(spellings[slug][0]), Slug: slug}
+		if err := DB.Where("slug = ?", slug).FirstOrCreate(&category).Error; err != nil {
+			return err
+		}
+
+		err := DB.Model(&models.Product{}).
+			Where("category_id IS NULL AND category IN ?", spellings[slug]).
+			Updates(map[string]interface{}{"category_id": category.ID, "category": category.Name}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateProductImages seeds the gallery of products that only have the
+// legacy single image.
+func migrateProductImages() error {
+	return DB.Exec(`
+		INSERT INTO product_images (product_id, path, alt_text, sort_order, is_primary, created_at)
+		SELECT p.id, p.image, p.title, 0, true, NOW()
+		FROM products p
+		WHERE p.image <> ''
+		AND NOT EXISTS (SELECT 1 FROM product_images i WHERE i.product_id = p.id)`).Error
+}
+
+// migrateUploads starts tracking files stored before uploads were recorded,
+// so cleanup can consider them once nothing points at them any more: every
+// file a product, gallery image, variant or rendition refers to. Files that
+// nothing referenced even then are not known to the database and are left
+// alone. Their grace period starts now.
+func migrateUploads() error {
+	return DB.Exec(`
+		INSERT INTO uploads (key, content_type, size, uploaded_at)
+		SELECT DISTINCT key, '', 0, NOW()
+		FROM (
+			SELECT image AS key FROM products
+			UNION SELECT path FROM product_images
+			UNION SELECT image FROM product_variants
+			UNION SELECT source_path FROM image_renditions
+		) referenced
+		WHERE key <> '' AND key NOT LIKE '%://%'
+		ON CONFLICT (key) DO NOTHING`).Error
+}
+
+// migrateProductSlugs gives products created before slugs existed one made
+// from their title, numbered in ID order where titles repeat.
+func migrateProductSlugs() error {
+	var products []models.Product
+	err := DB.Unscoped().Select("id", "title").Where("slug IS NULL OR slug = ''").Order("id").Find(&products).Error
+	if err != nil || len(products) == 0 {
+		return err
+	}
+
+	var existing, redirected []string
+	if err := DB.Unscoped().Model(&models.Product{}).Where("slug <> ''").Pluck("slug", &existing).Error; err != nil {
+		return err
+	}
+	if err := DB.Model(&models.ProductSlugRedirect{}).Pluck("slug", &redirected).Error; err != nil {
+		return err
+	}
+	taken := map[string]bool{}
+	for _, slug := range append(existing, redirected...) {
+		taken[slug] = true
+	}
+
+	for _, product := range products {
+		slug := utils.UniqueSlug(utils.ResourceSlug(product.Title, "product"), func(s string) bool { return taken[s] })
+		taken[slug] = true
+		if err := DB.Unscoped().Model(&product).UpdateColumn("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migratePriceHistory starts the history of products that have none with
+// their current price.
+func migratePriceHistory() error {
+	return DB.Exec(`
+		INSERT INTO price_changes (product_id, price, currency, source, changed_at)
+		SELECT p.id, p.price, p.currency, ?, NOW()
+		FROM products p
+		WHERE NOT EXISTS (SELECT 1 FROM price_changes h WHERE h.product_id = p.id)`,
+		models.PriceSourceInitial).Error
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"ecommerce/backend/database"
 	"ecommerce/backend/routes"
+	"ecommerce/backend/services"
 	// "ecommerce/backend/seeds"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -10,10 +11,15 @@ import (
 	"log"
 	"net/http"      
 	"os"
+	"path/filepath"
 )
 
 func createUploadsDir() {
-	if err := os.MkdirAll("uploads", 0755); err != nil {
+	local, ok := services.Files.(*services.LocalStorage)
+	if !ok {
+		return
+	}
+	if err := os.MkdirAll(filepath.Join(local.Dir, "uploads"), 0755); err != nil {
 		log.Printf("Warning: Failed to create Uploads directory: %v", err)
 	}
 }
@@ -27,6 +33,9 @@ func main() {
 	database.CreateDBIfNotExists()
 	database.ConnectDB()
 	database.Migrate()
+	if err := services.EnsureFraudRules(); err != nil {
+		log.Printf("Warning: Failed to create default fraud rules: %v", err)
+	}
 
 	var tables []string
 	database.DB.Raw(`
@@ -36,7 +45,17 @@ func main() {
     `).Scan(&tables)
 
 	fmt.Println("Tables in DB:", tables)
+	if err := services.ConfigureStorage(); err != nil {
+		log.Fatal("Failed to configure file storage: ", err)
+	}
 	createUploadsDir()
+	services.Subscribe("*", services.HandleWebhookEvent)
+	services.StartOutboxDispatcher()
+	services.StartWebhookWorker()
+	services.StartUploadCleanup()
+	services.StartRecommendationJob()
+	services.StartPriceScheduler()
+	services.StartPublishScheduler()
 
 	// db := database.DB
 	// seeder := seeds.NewSeeder(db)
@@ -49,7 +68,10 @@ func main() {
 	wd, _ := os.Getwd()
 	fmt.Printf("Current working directory: %s\n", wd)
 
-	app.StaticFS("/uploads", http.Dir("./uploads"))
+	// Other storage backends hand out their own URLs.
+	if local, ok := services.Files.(*services.LocalStorage); ok {
+		app.StaticFS("/uploads", http.Dir(filepath.Join(local.Dir, "uploads")))
+	}
 	
 	routes.RegisterRoutes(app)
 	
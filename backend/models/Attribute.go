@@ -0,0 +1,62 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	AttributeText    = "text"
+	AttributeNumber  = "number"
+	AttributeBoolean = "boolean"
+)
+
+// Attribute defines a typed spec, such as RAM or screen size, that products
+// in a category and its subcategories can carry.
+type Attribute struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	CategoryID uint   `gorm:"uniqueIndex:idx_attributes_category_slug" json:"category_id"`
+	Name       string `json:"name"`
+	Slug       string `gorm:"uniqueIndex:idx_attributes_category_slug;index" json:"slug"`
+	Type       string `gorm:"size:10" json:"type"`
+	Unit       string `gorm:"size:20" json:"unit"`
+	// Options restricts a text attribute to a fixed list of values.
+	Options pq.StringArray `gorm:"type:text[]" json:"options"`
+	// Facetable attributes get value counts in catalog responses.
+	Facetable bool      `json:"facetable"`
+	SortOrder int       `json:"sort_order"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProductAttributes maps attribute slugs to values, stored as JSONB. Values
+// keep their JSON type, so numbers compare as numbers in queries.
+type ProductAttributes map[string]interface{}
+
+func (a ProductAttributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(a)
+	return string(raw), err
+}
+
+func (a *ProductAttributes) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*a = ProductAttributes{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("unsupported type for product attributes")
+	}
+	return json.Unmarshal(raw, a)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// CartItem is a line in a user's shopping cart. Adding the same product and
+// variant again raises the quantity of the existing line.
+type CartItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	ProductID uint      `json:"product_id"`
+	VariantID *uint     `json:"variant_id"`
+	Quantity  int       `json:"quantity"`
+	Product   *Product  `json:"product,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
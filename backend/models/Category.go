@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Category struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `gorm:"uniqueIndex" json:"slug"`
+	ParentID  *uint      `gorm:"index" json:"parent_id"`
+	SortOrder int        `json:"sort_order"`
+	Children  []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
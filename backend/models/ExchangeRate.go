@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// ExchangeRate is how many units of Currency one unit of the base currency buys.
+type ExchangeRate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Currency  string    `gorm:"size:3;uniqueIndex" json:"currency"`
+	Rate      float64   `gorm:"type:numeric(20,10)" json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
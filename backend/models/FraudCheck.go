@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	FraudDecisionApproved = "approved"
+	FraudDecisionRejected = "rejected"
+)
+
+// FraudCheck is the screening result for one order and, for held orders,
+// the outcome of the admin review.
+type FraudCheck struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	OrderID    uint       `gorm:"uniqueIndex" json:"order_id"`
+	Score      int        `json:"score"`
+	Held       bool       `gorm:"index" json:"held"`
+	Hits       []FraudHit `gorm:"foreignKey:FraudCheckID" json:"hits"`
+	Decision   string     `gorm:"size:20" json:"decision"`
+	Note       string     `json:"note"`
+	ReviewedBy *uint      `json:"reviewed_by"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type FraudHit struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	FraudCheckID uint   `gorm:"index" json:"fraud_check_id"`
+	Rule         string `gorm:"size:50" json:"rule"`
+	Score        int    `json:"score"`
+	Detail       string `json:"detail"`
+}
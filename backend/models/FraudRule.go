@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// FraudRule is an admin-tunable check run against every new order. What
+// Threshold and WindowMinutes mean depends on the rule.
+type FraudRule struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Code          string    `gorm:"size:50;uniqueIndex" json:"code"`
+	Description   string    `json:"description"`
+	Enabled       bool      `json:"enabled"`
+	Threshold     float64   `json:"threshold"`
+	WindowMinutes int       `json:"window_minutes"`
+	Score         int       `json:"score"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
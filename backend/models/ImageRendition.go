@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ImageRendition is a resized copy of an uploaded image, looked up by the
+// path of the original.
+type ImageRendition struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	SourcePath string    `gorm:"index" json:"source_path"`
+	Size       string    `gorm:"size:20" json:"size"`
+	Format     string    `gorm:"size:10" json:"format"`
+	Path       string    `json:"path"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ImageSizes maps a rendition size to its URL in each available format,
+// e.g. sizes["thumbnail"]["webp"].
+type ImageSizes map[string]map[string]string
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	ModerationPending  = "pending"
+	ModerationApproved = "approved"
+	ModerationRejected = "rejected"
+)
+
+// Moderation is embedded in user-written content that must be approved
+// before it is shown publicly.
+type Moderation struct {
+	Status string `gorm:"size:20;default:pending;index" json:"status"`
+	// Flags lists why automatic screening held the content, e.g. "link" or
+	// "banned_word:spam".
+	Flags          pq.StringArray `gorm:"type:text[]" json:"flags"`
+	ModerationNote string         `json:"moderation_note,omitempty"`
+	ModeratedBy    *uint          `json:"moderated_by,omitempty"`
+	ModeratedAt    *time.Time     `json:"moderated_at,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Notification is an in-app message to a user, such as a rejected review.
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index" json:"user_id"`
+	Type      string     `gorm:"size:50" json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -1,5 +1,17 @@
 package models
 
+import "time"
+
+const (
+	OrderStatusPending       = "pending"
+	OrderStatusOnHold        = "on_hold"
+	OrderStatusPaid          = "paid"
+	OrderStatusPaymentFailed = "payment_failed"
+	OrderStatusShipped       = "shipped"
+	OrderStatusDelivered     = "delivered"
+	OrderStatusCancelled     = "cancelled"
+)
+
 type Order struct {
 	ID        uint `gorm:"primaryKey" json:"id"`
 
@@ -9,5 +21,57 @@ type Order struct {
 	ProductID uint    `json:"product_id"`
 	Product   Product `gorm:"foreignKey:ProductID" json:"product"`
 
+	VariantID *uint           `gorm:"index" json:"variant_id"`
+	Variant   *ProductVariant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
+	SKU       string          `json:"sku"`
+
 	Quantity  int `json:"quantity"`
-}
\ No newline at end of file
+
+	// Prices are captured in minor units when the order is placed.
+	UnitPrice int64  `json:"unit_price"`
+	Total     int64  `json:"total"`
+	Currency  string `gorm:"size:3" json:"currency"`
+
+	ShippingAddress string `json:"shipping_address"`
+	ShippingCountry string `gorm:"size:2" json:"shipping_country"`
+	BillingAddress  string `json:"billing_address"`
+	BillingCountry  string `gorm:"size:2" json:"billing_country"`
+	IPAddress       string `gorm:"size:45;index" json:"-"`
+
+	FraudScore int `json:"fraud_score"`
+
+	Status    string    `gorm:"size:20;default:pending;index" json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ValidOrderStatus(status string) bool {
+	switch status {
+	case OrderStatusPending, OrderStatusOnHold, OrderStatusPaid, OrderStatusPaymentFailed,
+		OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// orderTransitions lists the statuses each status can move to. Held orders
+// only leave review through the fraud review; cancelled and delivered orders
+// are final.
+var orderTransitions = map[string][]string{
+	OrderStatusPending:       {OrderStatusPaid, OrderStatusPaymentFailed, OrderStatusCancelled},
+	OrderStatusOnHold:        {OrderStatusPending, OrderStatusCancelled},
+	OrderStatusPaymentFailed: {OrderStatusPending},
+	OrderStatusPaid:          {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:       {OrderStatusDelivered},
+}
+
+// CanTransitionOrder reports whether an order may move from one status to
+// another.
+func CanTransitionOrder(from, to string) bool {
+	for _, status := range orderTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// OutboxEvent is a domain event written in the same transaction as the change
+// that caused it, so it is published even if the process dies right after.
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Type          string     `gorm:"size:100;index" json:"type"`
+	Payload       string     `gorm:"type:jsonb" json:"payload"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	PublishedAt   *time.Time `gorm:"index" json:"published_at"`
+	FailedAt      *time.Time `json:"failed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
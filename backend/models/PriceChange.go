@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	PriceSourceInitial       = "initial"
+	PriceSourceCreated       = "created"
+	PriceSourceManual        = "manual"
+	PriceSourceImport        = "import"
+	PriceSourceScheduleStart = "schedule_start"
+	PriceSourceScheduleEnd   = "schedule_end"
+)
+
+// PriceChange is an entry in a product's price history: the price it had
+// from ChangedAt until the next entry.
+type PriceChange struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ProductID  uint      `gorm:"index:idx_price_changes_product_changed" json:"product_id"`
+	Price      int64     `json:"price"`
+	Currency   string    `gorm:"size:3" json:"currency"`
+	Source     string    `gorm:"size:20" json:"source"`
+	ScheduleID *uint     `json:"schedule_id,omitempty"`
+	ChangedBy  *uint     `json:"-"`
+	ChangedAt  time.Time `gorm:"index:idx_price_changes_product_changed" json:"changed_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	PriceScheduleScheduled = "scheduled"
+	PriceScheduleActive    = "active"
+	PriceScheduleCompleted = "completed"
+	PriceScheduleCancelled = "cancelled"
+)
+
+// PriceSchedule changes a product's price at StartsAt. With EndsAt set it is
+// a sale, and the price before it is restored when it ends; without, the
+// change is permanent. Price is in minor units of Currency, the product's
+// currency when the schedule was created; it is not applied if that changes.
+// Only the product's own price changes, so products with per-currency or
+// variant prices cannot be scheduled.
+type PriceSchedule struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	ProductID uint       `gorm:"index" json:"product_id"`
+	Price     int64      `json:"price"`
+	Currency  string     `gorm:"size:3" json:"currency"`
+	StartsAt  time.Time  `gorm:"index" json:"starts_at"`
+	EndsAt    *time.Time `gorm:"index" json:"ends_at"`
+	Note      string     `json:"note"`
+	Status    string     `gorm:"size:20;default:scheduled;index" json:"status"`
+	// OriginalPrice is the price the schedule replaced, restored when a sale
+	// ends.
+	OriginalPrice *int64     `json:"original_price"`
+	AppliedAt     *time.Time `json:"applied_at"`
+	EndedAt       *time.Time `json:"ended_at"`
+	// Outcome explains how the schedule finished, e.g. when the price was
+	// left alone because it had been changed by hand during the sale.
+	Outcome   string    `json:"outcome,omitempty"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
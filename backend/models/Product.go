@@ -1,10 +1,51 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusPublished = "published"
+	ProductStatusArchived  = "archived"
+)
+
 type Product struct {
 	ID          uint   `gorm:"primaryKey" json:"id"`
 	Title       string `json:"title"`
-	Price       int    `json:"price"`
+	// Slug is the product's canonical URL name, made from its title.
+	Slug        string `gorm:"uniqueIndex" json:"slug"`
+	// SKU is optional; when set it is unique and identifies the product in
+	// bulk imports.
+	SKU         *string `gorm:"uniqueIndex" json:"sku"`
+	Price       int64  `json:"price"`
+	Currency    string `gorm:"size:3;default:USD" json:"currency"`
 	Description string `json:"description"`
+	// Category mirrors the category's name for clients that predate CategoryID.
 	Category    string `json:"category"`
+	CategoryID  *uint  `gorm:"index" json:"category_id"`
+	// Image is the primary gallery image, kept for clients that predate Images.
 	Image       string `json:"image"`
-}
\ No newline at end of file
+	// Stock is nil for products whose inventory is not tracked.
+	Stock       *int   `json:"stock"`
+	// Status controls visibility: only published products are public. A
+	// draft with PublishAt set goes public at that time.
+	Status      string     `gorm:"size:20;default:published;index" json:"status"`
+	PublishAt   *time.Time `gorm:"index" json:"publish_at"`
+	// DeletedAt is set while the product is in the trash. Trashed products
+	// are hidden from the catalog but still load for past orders.
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	// RatingAverage and ReviewCount summarize the product's reviews.
+	RatingAverage float64 `gorm:"default:0" json:"rating_average"`
+	ReviewCount   int     `gorm:"default:0" json:"review_count"`
+	// Attributes holds the typed specs defined for the product's category.
+	Attributes ProductAttributes `gorm:"type:jsonb;not null;default:'{}'" json:"attributes"`
+
+	Options     []ProductOption  `gorm:"foreignKey:ProductID" json:"options,omitempty"`
+	Variants    []ProductVariant `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
+	Images      []ProductImage   `gorm:"foreignKey:ProductID" json:"images,omitempty"`
+	ImageURL    string           `gorm:"-" json:"image_url,omitempty"`
+	ImageSizes  ImageSizes       `gorm:"-" json:"image_sizes,omitempty"`
+}
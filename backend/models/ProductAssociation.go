@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ProductAssociation records how many customers bought RelatedProductID
+// together with ProductID. Rows are rebuilt from order history by a periodic
+// job and exist in both directions.
+type ProductAssociation struct {
+	ProductID        uint      `gorm:"primaryKey;autoIncrement:false" json:"product_id"`
+	RelatedProductID uint      `gorm:"primaryKey;autoIncrement:false" json:"related_product_id"`
+	Count            int64     `json:"count"`
+	ComputedAt       time.Time `json:"computed_at"`
+}
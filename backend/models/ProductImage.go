@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type ProductImage struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	ProductID uint       `gorm:"index" json:"product_id"`
+	Path      string     `json:"path"`
+	AltText   string     `json:"alt_text"`
+	SortOrder int        `json:"sort_order"`
+	IsPrimary bool       `json:"is_primary"`
+	URL       string     `gorm:"-" json:"url,omitempty"`
+	Sizes     ImageSizes `gorm:"-" json:"sizes,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// ProductOption is an axis a product varies along, such as "Size".
+type ProductOption struct {
+	ID        uint                 `gorm:"primaryKey" json:"id"`
+	ProductID uint                 `gorm:"index" json:"product_id"`
+	Name      string               `json:"name"`
+	Position  int                  `json:"position"`
+	Values    []ProductOptionValue `gorm:"foreignKey:OptionID" json:"values"`
+}
+
+type ProductOptionValue struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	OptionID uint   `gorm:"index" json:"option_id"`
+	Value    string `json:"value"`
+	Position int    `json:"position"`
+}
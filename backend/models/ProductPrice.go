@@ -0,0 +1,9 @@
+package models
+
+// ProductPrice pins a product's price in a currency instead of converting it.
+type ProductPrice struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	ProductID uint   `gorm:"uniqueIndex:idx_product_price_currency" json:"product_id"`
+	Currency  string `gorm:"size:3;uniqueIndex:idx_product_price_currency" json:"currency"`
+	Amount    int64  `json:"amount"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ProductQuestion is a shopper's question on a product page. Questions and
+// their answers are only public once approved.
+type ProductQuestion struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	ProductID  uint            `gorm:"index" json:"product_id"`
+	UserID     uint            `gorm:"index" json:"user_id"`
+	Body       string          `json:"body"`
+	AuthorName string          `gorm:"->;-:migration" json:"author_name"`
+	Answers    []ProductAnswer `gorm:"foreignKey:QuestionID" json:"answers,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	Moderation
+}
+
+// ProductAnswer answers a question. Only admins and customers who received
+// the product may answer; the flags record which one wrote it.
+type ProductAnswer struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	QuestionID    uint      `gorm:"index" json:"question_id"`
+	UserID        uint      `gorm:"index" json:"user_id"`
+	Body          string    `json:"body"`
+	ByAdmin       bool      `json:"by_admin"`
+	VerifiedBuyer bool      `json:"verified_buyer"`
+	UpvoteCount   int       `gorm:"default:0" json:"upvote_count"`
+	AuthorName    string    `gorm:"->;-:migration" json:"author_name"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Moderation
+}
+
+// AnswerVote records a user's upvote of an answer.
+type AnswerVote struct {
+	AnswerID  uint      `gorm:"primaryKey" json:"answer_id"`
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
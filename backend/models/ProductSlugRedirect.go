@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ProductSlugRedirect is a slug a product had before its title changed. Old
+// links using it redirect to the product's current slug.
+type ProductSlugRedirect struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Slug      string    `gorm:"uniqueIndex" json:"slug"`
+	ProductID uint      `gorm:"index" json:"product_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ProductVariant is a sellable combination of option values. Price, Stock and
+// Image fall back to the product's when unset, so variants without their own
+// stock share the product's; Price is in the product's currency.
+type ProductVariant struct {
+	ID        uint                 `gorm:"primaryKey" json:"id"`
+	ProductID uint                 `gorm:"index" json:"product_id"`
+	SKU       string               `gorm:"uniqueIndex" json:"sku"`
+	Price     *int64               `json:"price"`
+	Stock     *int                 `json:"stock"`
+	Image     string               `json:"image"`
+	Options   []ProductOptionValue `gorm:"many2many:product_variant_options" json:"options"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
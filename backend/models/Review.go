@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	ReviewStatusPending  = ModerationPending
+	ReviewStatusApproved = ModerationApproved
+	ReviewStatusRejected = ModerationRejected
+)
+
+// Review is a customer's rating of a product they received. Each customer
+// reviews a product at most once. Only approved reviews are public and count
+// towards the product's rating.
+type Review struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ProductID    uint      `gorm:"uniqueIndex:idx_review_product_user" json:"product_id"`
+	UserID       uint      `gorm:"uniqueIndex:idx_review_product_user" json:"user_id"`
+	OrderID      uint      `json:"order_id"`
+	Rating       int       `json:"rating"`
+	Title        string    `gorm:"size:200" json:"title"`
+	Body         string    `json:"body"`
+	HelpfulCount int       `gorm:"default:0" json:"helpful_count"`
+	AuthorName   string    `gorm:"->;-:migration" json:"author_name"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Moderation
+}
+
+// ReviewVote records that a user found a review helpful.
+type ReviewVote struct {
+	ReviewID  uint      `gorm:"primaryKey" json:"review_id"`
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BannedWord is a word or phrase that holds reviews, questions and answers
+// for moderation.
+type BannedWord struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Word      string    `gorm:"size:100;uniqueIndex" json:"word"`
+	CreatedAt time.Time `json:"created_at"`
+}
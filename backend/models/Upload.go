@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Upload records a file written to storage, so files that nothing references
+// any more can be found and removed. Renditions are tracked through
+// ImageRendition and go with their original.
+type Upload struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Key         string    `gorm:"uniqueIndex" json:"key"`
+	ContentType string    `gorm:"size:100" json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedAt  time.Time `gorm:"index" json:"uploaded_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type Webhook struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	URL       string         `json:"url"`
+	Secret    string         `json:"secret"`
+	Events    pq.StringArray `gorm:"type:text[]" json:"events"`
+	Active    bool           `json:"active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func (w Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Wishlist is a named list of products a user wants. Every user has one
+// default list, created on first use. A list is public to anyone with its
+// share token while the token is set.
+type Wishlist struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	UserID     uint           `gorm:"index" json:"user_id"`
+	Name       string         `gorm:"size:100" json:"name"`
+	IsDefault  bool           `json:"is_default"`
+	ShareToken *string        `gorm:"size:64;uniqueIndex" json:"share_token"`
+	Items      []WishlistItem `gorm:"foreignKey:WishlistID" json:"items,omitempty"`
+	ItemCount  int            `gorm:"->;-:migration" json:"item_count"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+type WishlistItem struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	WishlistID uint      `gorm:"index" json:"wishlist_id"`
+	ProductID  uint      `gorm:"index" json:"product_id"`
+	VariantID  *uint     `json:"variant_id"`
+	Product    *Product  `json:"product,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
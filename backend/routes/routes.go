@@ -14,7 +14,21 @@ func RegisterRoutes(r *gin.Engine) {
 	api.POST("/register", controllers.Register)
 	api.POST("/login", controllers.Login)
 	api.GET("/products", controllers.GetProducts)
+	api.GET("/products/search", controllers.SearchProducts)
+	api.GET("/products/:id/variants", controllers.GetProductVariants)
+	api.GET("/products/:id/images", controllers.GetProductImages)
+	api.GET("/products/:id/reviews", controllers.GetProductReviews)
+	api.GET("/products/:id/questions", controllers.GetProductQuestions)
+	api.GET("/products/:id/recommendations", controllers.GetProductRecommendations)
+	api.GET("/products/:id/price-history", controllers.GetPriceHistory)
+	api.GET("/wishlists/shared/:token", controllers.GetSharedWishlist)
 	api.GET("/products/:id", controllers.GetProduct)
+	api.GET("/exchange-rates", controllers.GetExchangeRates)
+	api.GET("/categories", controllers.GetCategories)
+	api.GET("/categories/tree", controllers.GetCategoryTree)
+	api.GET("/categories/:id", controllers.GetCategory)
+	api.GET("/categories/:id/products", controllers.GetCategoryProducts)
+	api.GET("/categories/:id/attributes", controllers.GetCategoryAttributes)
 	
 	// auth
 	protected := api.Group("/")
@@ -37,14 +51,119 @@ func RegisterRoutes(r *gin.Engine) {
 		protected.POST("/orders", controllers.CreateOrder)
 		protected.PUT("/orders/:id", controllers.UpdateOrder)
 		protected.DELETE("/orders/:id", controllers.DeleteOrder)
+
+		protected.POST("/products/:id/reviews", controllers.CreateProductReview)
+		protected.PUT("/products/:id/reviews/:reviewId", controllers.UpdateProductReview)
+		protected.DELETE("/products/:id/reviews/:reviewId", controllers.DeleteProductReview)
+		protected.POST("/products/:id/reviews/:reviewId/helpful", controllers.VoteReviewHelpful)
+		protected.DELETE("/products/:id/reviews/:reviewId/helpful", controllers.UnvoteReviewHelpful)
+		protected.POST("/products/:id/questions", controllers.CreateProductQuestion)
+		protected.POST("/products/:id/questions/:questionId/answers", controllers.CreateQuestionAnswer)
+		protected.POST("/products/:id/questions/:questionId/answers/:answerId/upvote", controllers.UpvoteAnswer)
+		protected.DELETE("/products/:id/questions/:questionId/answers/:answerId/upvote", controllers.RemoveAnswerUpvote)
+		protected.GET("/me/reviews", controllers.GetMyReviews)
+
+		protected.GET("/notifications", controllers.GetNotifications)
+		protected.POST("/notifications/:id/read", controllers.MarkNotificationRead)
+
+		protected.GET("/wishlists", controllers.GetWishlists)
+		protected.POST("/wishlists", controllers.CreateWishlist)
+		protected.GET("/wishlists/:id", controllers.GetWishlist)
+		protected.PUT("/wishlists/:id", controllers.UpdateWishlist)
+		protected.DELETE("/wishlists/:id", controllers.DeleteWishlist)
+		protected.POST("/wishlists/:id/items", controllers.AddWishlistItem)
+		protected.DELETE("/wishlists/:id/items/:itemId", controllers.DeleteWishlistItem)
+		protected.POST("/wishlists/:id/items/:itemId/move-to-cart", controllers.MoveWishlistItemToCart)
+		protected.POST("/wishlists/:id/share", controllers.ShareWishlist)
+		protected.DELETE("/wishlists/:id/share", controllers.UnshareWishlist)
+
+		protected.GET("/cart", controllers.GetCart)
+		protected.POST("/cart/items", controllers.AddCartItem)
+		protected.PUT("/cart/items/:id", controllers.UpdateCartItem)
+		protected.DELETE("/cart/items/:id", controllers.DeleteCartItem)
+		protected.GET("/cart/recommendations", controllers.GetCartRecommendations)
 		
 		// admin
 		admin := protected.Group("/")
 		admin.Use(middleware.AdminOnly())
 		{
 			admin.POST("/products", controllers.CreateProduct)
+			admin.POST("/products/import", controllers.ImportProducts)
+			admin.POST("/recommendations/refresh", controllers.RefreshRecommendations)
 			admin.PUT("/products/:id", controllers.UpdateProduct)
 			admin.DELETE("/products/:id", controllers.DeleteProduct)
+			admin.GET("/products/trash", controllers.GetTrashedProducts)
+			admin.POST("/products/:id/restore", controllers.RestoreProduct)
+			admin.GET("/products/unpublished", controllers.GetUnpublishedProducts)
+			admin.GET("/products/:id/preview", controllers.PreviewProduct)
+			admin.PUT("/products/:id/status", controllers.SetProductStatus)
+
+			admin.POST("/categories", controllers.CreateCategory)
+			admin.PUT("/categories/:id", controllers.UpdateCategory)
+			admin.DELETE("/categories/:id", controllers.DeleteCategory)
+			admin.POST("/categories/:id/attributes", controllers.CreateCategoryAttribute)
+			admin.PUT("/attributes/:id", controllers.UpdateAttribute)
+			admin.DELETE("/attributes/:id", controllers.DeleteAttribute)
+
+			admin.POST("/products/:id/options", controllers.CreateProductOption)
+			admin.DELETE("/products/:id/options/:optionId", controllers.DeleteProductOption)
+			admin.POST("/products/:id/variants", controllers.CreateProductVariant)
+			admin.PUT("/products/:id/variants/:variantId", controllers.UpdateProductVariant)
+			admin.DELETE("/products/:id/variants/:variantId", controllers.DeleteProductVariant)
+
+			admin.POST("/products/:id/images", controllers.UploadProductImages)
+			admin.PUT("/products/:id/images/order", controllers.ReorderProductImages)
+			admin.PUT("/products/:id/images/:imageId", controllers.UpdateProductImage)
+			admin.DELETE("/products/:id/images/:imageId", controllers.DeleteProductImage)
+
+			admin.GET("/products/:id/prices", controllers.GetProductPrices)
+			admin.PUT("/products/:id/prices/:currency", controllers.SetProductPrice)
+			admin.DELETE("/products/:id/prices/:currency", controllers.DeleteProductPrice)
+
+			admin.GET("/products/:id/price-schedules", controllers.GetPriceSchedules)
+			admin.POST("/products/:id/price-schedules", controllers.CreatePriceSchedule)
+			admin.DELETE("/products/:id/price-schedules/:scheduleId", controllers.CancelPriceSchedule)
+
+			admin.PUT("/exchange-rates/:currency", controllers.UpsertExchangeRate)
+			admin.DELETE("/exchange-rates/:currency", controllers.DeleteExchangeRate)
+
+			admin.GET("/webhook-events", controllers.GetWebhookEvents)
+			admin.GET("/webhooks", controllers.GetWebhooks)
+			admin.POST("/webhooks", controllers.CreateWebhook)
+			admin.GET("/webhooks/:id", controllers.GetWebhook)
+			admin.PUT("/webhooks/:id", controllers.UpdateWebhook)
+			admin.DELETE("/webhooks/:id", controllers.DeleteWebhook)
+			admin.POST("/webhooks/:id/rotate-secret", controllers.RotateWebhookSecret)
+			admin.GET("/webhooks/:id/deliveries", controllers.GetWebhookDeliveries)
+			admin.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", controllers.RedeliverWebhook)
+
+			admin.GET("/outbox", controllers.GetOutboxEvents)
+			admin.POST("/outbox/:id/retry", controllers.RetryOutboxEvent)
+
+			admin.GET("/uploads/orphans", controllers.GetOrphanedUploads)
+			admin.POST("/uploads/cleanup", controllers.CleanupUploads)
+
+			admin.GET("/reviews", controllers.GetReviewQueue)
+			admin.POST("/reviews/:id/moderate", controllers.ModerateReview)
+			admin.GET("/questions", controllers.GetQuestionQueue)
+			admin.POST("/questions/:id/moderate", controllers.ModerateQuestion)
+			admin.GET("/answers", controllers.GetAnswerQueue)
+			admin.POST("/answers/:id/moderate", controllers.ModerateAnswer)
+			admin.GET("/moderation/banned-words", controllers.GetBannedWords)
+			admin.POST("/moderation/banned-words", controllers.CreateBannedWord)
+			admin.DELETE("/moderation/banned-words/:id", controllers.DeleteBannedWord)
+
+			admin.PUT("/orders/:id/status", controllers.UpdateOrderStatus)
+
+			admin.GET("/fraud/rules", controllers.GetFraudRules)
+			admin.PUT("/fraud/rules/:code", controllers.UpdateFraudRule)
+			admin.GET("/fraud/orders", controllers.GetHeldOrders)
+			admin.GET("/fraud/orders/:id", controllers.GetFraudCheck)
+			admin.POST("/fraud/orders/:id/review", controllers.ReviewHeldOrder)
+
+			admin.GET("/reports/sales", controllers.GetSalesReport)
+			admin.GET("/reports/sales/categories", controllers.GetSalesByCategory)
+			admin.GET("/reports/sales/products", controllers.GetSalesByProduct)
 		}
 	}
 }
\ No newline at end of file
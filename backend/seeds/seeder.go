@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"time"
 	"ecommerce/backend/models"
+	"ecommerce/backend/services"
 	"gorm.io/gorm"
 )
 
@@ -26,143 +27,143 @@ var imageOptions = []string{
 var sampleProducts = []models.Product{
 	{
 		Title:       "iPhone 15 Pro",
-		Price:       999,
+		Price:       99900,
 		Description: "Latest iPhone with A17 Pro chip and titanium design",
 		Category:    "Smartphones",
 	},
 	{
 		Title:       "Samsung Galaxy S24",
-		Price:       899,
+		Price:       89900,
 		Description: "Premium Android smartphone with AI features",
 		Category:    "Smartphones",
 	},
 	{
 		Title:       "Google Pixel 8 Pro",
-		Price:       999,
+		Price:       99900,
 		Description: "Google's flagship with Tensor G3 chip and advanced camera",
 		Category:    "Smartphones",
 	},
 	{
 		Title:       "OnePlus 12",
-		Price:       799,
+		Price:       79900,
 		Description: "Flagship killer with Snapdragon 8 Gen 3",
 		Category:    "Smartphones",
 	},
 	{
 		Title:       "Xiaomi 14 Pro",
-		Price:       899,
+		Price:       89900,
 		Description: "Leica co-engineered camera system",
 		Category:    "Smartphones",
 	},
 
 	{
 		Title:       "MacBook Pro 16\" M3 Max",
-		Price:       3499,
+		Price:       349900,
 		Description: "Professional laptop for extreme performance",
 		Category:    "Laptops",
 	},
 	{
 		Title:       "Dell XPS 15",
-		Price:       1899,
+		Price:       189900,
 		Description: "Premium Windows laptop with OLED display",
 		Category:    "Laptops",
 	},
 	{
 		Title:       "Lenovo ThinkPad X1 Carbon",
-		Price:       1699,
+		Price:       169900,
 		Description: "Business laptop with legendary keyboard",
 		Category:    "Laptops",
 	},
 	{
 		Title:       "ASUS ROG Zephyrus G14",
-		Price:       1599,
+		Price:       159900,
 		Description: "Gaming laptop with RTX 4060",
 		Category:    "Laptops",
 	},
 	{
 		Title:       "Microsoft Surface Laptop 5",
-		Price:       1299,
+		Price:       129900,
 		Description: "Sleek Windows laptop with touchscreen",
 		Category:    "Laptops",
 	},
 
 	{
 		Title:       "Sony WH-1000XM5",
-		Price:       399,
+		Price:       39900,
 		Description: "Industry-leading noise cancellation",
 		Category:    "Headphones",
 	},
 	{
 		Title:       "Apple AirPods Max",
-		Price:       549,
+		Price:       54900,
 		Description: "Premium over-ear headphones with spatial audio",
 		Category:    "Headphones",
 	},
 	{
 		Title:       "Bose QuietComfort Ultra",
-		Price:       429,
+		Price:       42900,
 		Description: "Immersive audio with noise cancelling",
 		Category:    "Headphones",
 	},
 	{
 		Title:       "Sennheiser Momentum 4",
-		Price:       349,
+		Price:       34900,
 		Description: "Hi-Fi sound with 60-hour battery",
 		Category:    "Headphones",
 	},
 	{
 		Title:       "Jabra Elite 85h",
-		Price:       229,
+		Price:       22900,
 		Description: "Smart sound personalization",
 		Category:    "Headphones",
 	},
 
 	{
 		Title:       "iPad Pro 12.9\" M2",
-		Price:       1099,
+		Price:       109900,
 		Description: "Professional tablet with Liquid Retina XDR",
 		Category:    "Tablets",
 	},
 	{
 		Title:       "Samsung Galaxy Tab S9 Ultra",
-		Price:       1199,
+		Price:       119900,
 		Description: "Android tablet with S Pen included",
 		Category:    "Tablets",
 	},
 	{
 		Title:       "Microsoft Surface Pro 9",
-		Price:       1299,
+		Price:       129900,
 		Description: "2-in-1 laptop and tablet",
 		Category:    "Tablets",
 	},
 	{
 		Title:       "Lenovo Tab P12 Pro",
-		Price:       899,
+		Price:       89900,
 		Description: "OLED display with Dolby Vision",
 		Category:    "Tablets",
 	},
 
 	{
 		Title:       "Apple Watch Series 9",
-		Price:       399,
+		Price:       39900,
 		Description: "Smartwatch with advanced health features",
 		Category:    "Wearables",
 	},
 	{
 		Title:       "Samsung Galaxy Watch 6 Classic",
-		Price:       369,
+		Price:       36900,
 		Description: "Rotating bezel smartwatch",
 		Category:    "Wearables",
 	},
 	{
 		Title:       "Google Pixel Watch 2",
-		Price:       349,
+		Price:       34900,
 		Description: "Fitbit integration and health tracking",
 		Category:    "Wearables",
 	},
 	{
 		Title:       "Garmin Fenix 7",
-		Price:       699,
+		Price:       69900,
 		Description: "Premium multisport GPS watch",
 		Category:    "Wearables",
 	},
@@ -192,6 +193,9 @@ func (s *Seeder) clean() error {
 	if err := s.DB.Exec("DELETE FROM products").Error; err != nil {
 		return err
 	}
+	if err := s.DB.Exec("DELETE FROM product_slug_redirects").Error; err != nil {
+		return err
+	}
 	
 	if s.DB.Dialector.Name() == "mysql" {
 		s.DB.Exec("ALTER TABLE products AUTO_INCREMENT = 1")
@@ -206,6 +210,16 @@ func (s *Seeder) seedProducts() (int, error) {
 
 	for i, product := range sampleProducts {
 		product.Image = getRandomImage()
+		product.Currency = "USD"
+
+		if err := services.ApplyProductCategory(s.DB, &product); err != nil {
+			log.Printf("❌ Failed to resolve category for product %d: %s - %v\n", i+1, product.Title, err)
+			continue
+		}
+		if err := services.ApplyProductSlug(s.DB, &product, ""); err != nil {
+			log.Printf("❌ Failed to pick a slug for product %d: %s - %v\n", i+1, product.Title, err)
+			continue
+		}
 
 		result := s.DB.Create(&product)
 		if result.Error != nil {
@@ -214,8 +228,8 @@ func (s *Seeder) seedProducts() (int, error) {
 		}
 
 		createdCount++
-		log.Printf("✅ Created product %d/%d: %s ($%d) - Image: %s\n",
-			i+1, len(sampleProducts), product.Title, product.Price, product.Image)
+		log.Printf("✅ Created product %d/%d: %s (%d %s) - Image: %s\n",
+			i+1, len(sampleProducts), product.Title, product.Price, product.Currency, product.Image)
 	}
 
 	return createdCount, nil
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrAttributeTypeConflict = errors.New("attribute slug is already used with a different type")
+
+// AttributeError reports a product attribute value that does not fit its
+// definition.
+type AttributeError struct {
+	Slug   string
+	Reason string
+}
+
+func (e *AttributeError) Error() string {
+	return fmt.Sprintf("attribute %s %s", e.Slug, e.Reason)
+}
+
+func ValidAttributeType(t string) bool {
+	return t == models.AttributeText || t == models.AttributeNumber || t == models.AttributeBoolean
+}
+
+// AncestorCategoryIDs returns the category and every category above it.
+func AncestorCategoryIDs(db *gorm.DB, id uint) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id, parent_id FROM categories WHERE id = ?
+			UNION ALL
+			SELECT c.id, c.parent_id FROM categories c JOIN tree t ON c.id = t.parent_id
+		)
+		SELECT id FROM tree`, id).Scan(&ids).Error
+	return ids, err
+}
+
+// CategoryAttributes returns the attributes that apply to a category: its
+// own and those inherited from its ancestors.
+func CategoryAttributes(db *gorm.DB, categoryID uint) ([]models.Attribute, error) {
+	ids, err := AncestorCategoryIDs(db, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var attributes []models.Attribute
+	err = db.Where("category_id IN ?", ids).Order("sort_order, name").Find(&attributes).Error
+	return attributes, err
+}
+
+// CheckAttributeSlug makes sure a slug means the same type everywhere, so
+// catalog filters can be applied across categories.
+func CheckAttributeSlug(db *gorm.DB, slug, attributeType string) error {
+	var conflicts int64
+	err := db.Model(&models.Attribute{}).Where("slug = ? AND type <> ?", slug, attributeType).Count(&conflicts).Error
+	if err != nil {
+		return err
+	}
+	if conflicts > 0 {
+		return ErrAttributeTypeConflict
+	}
+	return nil
+}
+
+// ApplyProductAttributes checks a product's attributes against those of its
+// category before it is saved, and normalizes the values. Null values are
+// dropped, which is how a single attribute is cleared.
+func ApplyProductAttributes(tx *gorm.DB, product *models.Product) error {
+	if product.Attributes == nil {
+		product.Attributes = models.ProductAttributes{}
+	}
+	for slug, value := range product.Attributes {
+		if value == nil {
+			delete(product.Attributes, slug)
+		}
+	}
+	if len(product.Attributes) == 0 {
+		return nil
+	}
+	if product.CategoryID == nil {
+		return &AttributeError{Slug: firstSlug(product.Attributes), Reason: "needs the product to have a category"}
+	}
+
+	definitions, err := CategoryAttributes(tx, *product.CategoryID)
+	if err != nil {
+		return err
+	}
+	bySlug := map[string]models.Attribute{}
+	for _, definition := range definitions {
+		bySlug[definition.Slug] = definition
+	}
+
+	for slug, value := range product.Attributes {
+		definition, ok := bySlug[slug]
+		if !ok {
+			return &AttributeError{Slug: slug, Reason: "is not defined for this category"}
+		}
+		normalized, err := normalizeAttribute(definition, value)
+		if err != nil {
+			return err
+		}
+		product.Attributes[slug] = normalized
+	}
+	return nil
+}
+
+func normalizeAttribute(definition models.Attribute, value interface{}) (interface{}, error) {
+	switch definition.Type {
+	case models.AttributeNumber:
+		if number, ok := value.(float64); ok {
+			return number, nil
+		}
+		return nil, &AttributeError{Slug: definition.Slug, Reason: "must be a number"}
+	case models.AttributeBoolean:
+		if flag, ok := value.(bool); ok {
+			return flag, nil
+		}
+		return nil, &AttributeError{Slug: definition.Slug, Reason: "must be true or false"}
+	default:
+		text, ok := value.(string)
+		if !ok || strings.TrimSpace(text) == "" {
+			return nil, &AttributeError{Slug: definition.Slug, Reason: "must be a non-empty string"}
+		}
+		return ResolveAttributeText(definition, text)
+	}
+}
+
+// ResolveAttributeText trims a text value and, for attributes with options,
+// matches it case-insensitively to the option's spelling.
+func ResolveAttributeText(definition models.Attribute, text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if len(definition.Options) == 0 {
+		return text, nil
+	}
+	for _, option := range definition.Options {
+		if strings.EqualFold(option, text) {
+			return option, nil
+		}
+	}
+	return "", &AttributeError{Slug: definition.Slug, Reason: "must be one of " + strings.Join(definition.Options, ", ")}
+}
+
+func firstSlug(attributes models.ProductAttributes) string {
+	slugs := make([]string, 0, len(attributes))
+	for slug := range attributes {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	return slugs[0]
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type Facet struct {
+	Attribute string       `json:"attribute"`
+	Name      string       `json:"name"`
+	Type      string       `json:"type"`
+	Unit      string       `json:"unit,omitempty"`
+	Values    []FacetValue `json:"values"`
+}
+
+// AttributeFacets counts the products in a result set by the value of each
+// facetable attribute. products is the filtered product query, before paging.
+func AttributeFacets(db *gorm.DB, products *gorm.DB) ([]Facet, error) {
+	var rows []struct {
+		Key   string
+		Value string
+		Count int64
+	}
+	err := db.Table("(?) AS p", products.Select("products.attributes")).
+		Joins("CROSS JOIN LATERAL jsonb_each_text(p.attributes) AS a").
+		Select("a.key, a.value, COUNT(*) AS count").
+		Group("a.key, a.value").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []Facet{}, nil
+	}
+
+	slugs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		slugs = append(slugs, row.Key)
+	}
+	// A slug can be defined by several categories; the type is the same for
+	// all of them, so the first definition labels the facet.
+	var definitions []models.Attribute
+	err = db.Where("slug IN ? AND facetable", slugs).Order("sort_order, name, id").Find(&definitions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	facets := []Facet{}
+	index := map[string]int{}
+	for _, definition := range definitions {
+		if _, seen := index[definition.Slug]; seen {
+			continue
+		}
+		index[definition.Slug] = len(facets)
+		facets = append(facets, Facet{
+			Attribute: definition.Slug,
+			Name:      definition.Name,
+			Type:      definition.Type,
+			Unit:      definition.Unit,
+			Values:    []FacetValue{},
+		})
+	}
+	for _, row := range rows {
+		if i, ok := index[row.Key]; ok {
+			facets[i].Values = append(facets[i].Values, FacetValue{Value: row.Value, Count: row.Count})
+		}
+	}
+
+	for i := range facets {
+		values := facets[i].Values
+		numeric := facets[i].Type == models.AttributeNumber
+		sort.Slice(values, func(a, b int) bool {
+			if numeric {
+				x, _ := strconv.ParseFloat(values[a].Value, 64)
+				y, _ := strconv.ParseFloat(values[b].Value, 64)
+				return x < y
+			}
+			return values[a].Value < values[b].Value
+		})
+	}
+	return facets, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrProductNotFound = errors.New("product not found")
+var ErrVariantNotFound = errors.New("variant not found")
+var ErrVariantRequired = errors.New("variant_id is required for this product")
+
+// CheckVariant makes sure a product is available and that the variant, if
+// any, belongs to it. Products with variants must be bought as one.
+func CheckVariant(tx *gorm.DB, productID uint, variantID *uint) error {
+	var product models.Product
+	if err := tx.Select("id").Scopes(PublishedProducts).First(&product, productID).Error; err != nil {
+		return ErrProductNotFound
+	}
+
+	if variantID != nil {
+		var count int64
+		tx.Model(&models.ProductVariant{}).Where("id = ? AND product_id = ?", *variantID, productID).Count(&count)
+		if count == 0 {
+			return ErrVariantNotFound
+		}
+		return nil
+	}
+
+	var variants int64
+	tx.Model(&models.ProductVariant{}).Where("product_id = ?", productID).Count(&variants)
+	if variants > 0 {
+		return ErrVariantRequired
+	}
+	return nil
+}
+
+// AddToCart puts a product in the user's cart, merging with an existing line
+// for the same product and variant. The merge is a single upsert, so
+// concurrent adds cannot create two lines.
+func AddToCart(tx *gorm.DB, userID, productID uint, variantID *uint, quantity int) (models.CartItem, error) {
+	if err := CheckVariant(tx, productID, variantID); err != nil {
+		return models.CartItem{}, err
+	}
+
+	item := models.CartItem{UserID: userID, ProductID: productID, VariantID: variantID, Quantity: quantity}
+	err := tx.Clauses(clause.OnConflict{
+		// Matches the idx_cart_items_unique_line expression index.
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "product_id"}, {Name: "COALESCE(variant_id, 0)", Raw: true}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity":   gorm.Expr("cart_items.quantity + EXCLUDED.quantity"),
+			"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(&item).Error
+	if err != nil {
+		return item, err
+	}
+
+	err = tx.Where("user_id = ? AND product_id = ?", userID, productID).
+		Scopes(variantMatch(variantID)).
+		First(&item).Error
+	return item, err
+}
+
+// variantMatch matches rows for the given variant, or for no variant when it
+// is nil.
+func variantMatch(variantID *uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if variantID != nil {
+			return db.Where("variant_id = ?", *variantID)
+		}
+		return db.Where("variant_id IS NULL")
+	}
+}
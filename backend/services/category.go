@@ -0,0 +1,107 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"ecommerce/backend/utils"
+	"errors"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrCategoryNotFound = errors.New("category not found")
+
+// DescendantCategoryIDs returns the category and every category below it.
+func DescendantCategoryIDs(db *gorm.DB, id uint) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id = ?
+			UNION ALL
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT id FROM tree`, id).Scan(&ids).Error
+	return ids, err
+}
+
+// CategorySlug turns a category name or requested slug into a slug, or ""
+// when it has no letters or digits. Slugs made only of digits are prefixed
+// so they are not mistaken for category IDs.
+func CategorySlug(s string) string {
+	if utils.Slugify(s) == "" {
+		return ""
+	}
+	return utils.ResourceSlug(s, "category")
+}
+
+// FindOrCreateCategory resolves a free-text category name by slug, so names
+// that differ only in case or punctuation land in the same category.
+func FindOrCreateCategory(tx *gorm.DB, name string) (models.Category, error) {
+	name = strings.TrimSpace(name)
+	category := models.Category{Name: name, Slug: CategorySlug(name)}
+	if category.Slug == "" {
+		return category, ErrCategoryNotFound
+	}
+
+	err := tx.Where("slug = ?", category.Slug).FirstOrCreate(&category).Error
+	return category, err
+}
+
+// ApplyProductCategory links a product to its category before it is saved.
+// CategoryID wins when set; otherwise the legacy Category string is resolved
+// into a category. Either way Category is rewritten to the canonical name.
+func ApplyProductCategory(tx *gorm.DB, product *models.Product) error {
+	if product.CategoryID != nil {
+		var category models.Category
+		if err := tx.First(&category, *product.CategoryID).Error; err != nil {
+			return ErrCategoryNotFound
+		}
+		product.Category = category.Name
+		return nil
+	}
+
+	if strings.TrimSpace(product.Category) == "" {
+		product.Category = ""
+		return nil
+	}
+
+	category, err := FindOrCreateCategory(tx, product.Category)
+	if err != nil {
+		return err
+	}
+	product.CategoryID = &category.ID
+	product.Category = category.Name
+	return nil
+}
+
+// BuildCategoryTree nests a flat list of categories under their parents,
+// ordered by SortOrder then name at every level.
+func BuildCategoryTree(categories []models.Category) []models.Category {
+	children := map[uint][]models.Category{}
+	var roots []models.Category
+
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			if nodes[i].SortOrder != nodes[j].SortOrder {
+				return nodes[i].SortOrder < nodes[j].SortOrder
+			}
+			return nodes[i].Name < nodes[j].Name
+		})
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+
+	return attach(roots)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const uploadCleanupInterval = 6 * time.Hour
+
+// UploadGracePeriod is how long an unreferenced upload is kept, from
+// UPLOAD_GRACE_HOURS (default 24). It covers files uploaded for a record that
+// has not been saved yet.
+func UploadGracePeriod() time.Duration {
+	return envHours("UPLOAD_GRACE_HOURS", 24*time.Hour)
+}
+
+func envHours(name string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(name); value != "" {
+		if hours, err := time.ParseDuration(value + "h"); err == nil && hours >= 0 {
+			return hours
+		}
+	}
+	return fallback
+}
+
+type OrphanedUpload struct {
+	Key        string    `json:"key"`
+	Size       int64     `json:"size"`
+	UploadedAt time.Time `json:"uploaded_at"`
+	Renditions []string  `json:"renditions"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type CleanupReport struct {
+	DryRun bool             `json:"dry_run"`
+	Cutoff time.Time        `json:"cutoff"`
+	Files  []OrphanedUpload `json:"files"`
+	Count  int              `json:"count"`
+	Bytes  int64            `json:"bytes"`
+}
+
+// TrashRetention is how long a trashed product keeps its files, from
+// TRASH_RETENTION_HOURS (default 720, 30 days). After that its images count
+// as unreferenced, so a product restored later may come back without them.
+func TrashRetention() time.Duration {
+	return envHours("TRASH_RETENTION_HOURS", 30*24*time.Hour)
+}
+
+// orphanedUploads selects uploads older than cutoff that no product, gallery
+// image or variant points at. Products in the trash still count as references
+// so they can be restored, until they have been there longer than
+// TrashRetention.
+func orphanedUploads(db *gorm.DB, cutoff time.Time) *gorm.DB {
+	trashCutoff := time.Now().Add(-TrashRetention())
+	const kept = "(products.deleted_at IS NULL OR products.deleted_at >= ?)"
+	return db.Model(&models.Upload{}).
+		Where("uploads.uploaded_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM products WHERE products.image = uploads.key AND "+kept+")", trashCutoff).
+		Where("NOT EXISTS (SELECT 1 FROM product_images JOIN products ON products.id = product_images.product_id "+
+			"WHERE product_images.path = uploads.key AND "+kept+")", trashCutoff).
+		Where("NOT EXISTS (SELECT 1 FROM product_variants JOIN products ON products.id = product_variants.product_id "+
+			"WHERE product_variants.image = uploads.key AND "+kept+")", trashCutoff)
+}
+
+// CleanupOrphanedUploads removes unreferenced uploads older than grace, along
+// with their renditions. With dryRun set it only reports what would go.
+func CleanupOrphanedUploads(ctx context.Context, grace time.Duration, dryRun bool) (CleanupReport, error) {
+	report := CleanupReport{DryRun: dryRun, Cutoff: time.Now().Add(-grace), Files: []OrphanedUpload{}}
+
+	var uploads []models.Upload
+	if err := orphanedUploads(database.DB, report.Cutoff).Order("uploads.id").Find(&uploads).Error; err != nil {
+		return report, err
+	}
+
+	for _, upload := range uploads {
+		var renditions []models.ImageRendition
+		database.DB.Where("source_path = ?", upload.Key).Find(&renditions)
+
+		orphan := OrphanedUpload{Key: upload.Key, Size: upload.Size, UploadedAt: upload.UploadedAt, Renditions: []string{}}
+		for _, r := range renditions {
+			orphan.Renditions = append(orphan.Renditions, r.Path)
+		}
+
+		if !dryRun {
+			removed, err := removeUpload(ctx, upload, report.Cutoff)
+			if err != nil {
+				log.Printf("Upload cleanup: failed to remove %s: %v", upload.Key, err)
+				orphan.Error = err.Error()
+			} else if !removed {
+				// Referenced or claimed by another instance since the scan.
+				continue
+			}
+		}
+
+		report.Files = append(report.Files, orphan)
+		if orphan.Error == "" {
+			report.Count++
+			report.Bytes += orphan.Size
+		}
+	}
+	return report, nil
+}
+
+// removeUpload deletes an upload's files and rows. The row is locked and
+// checked again first, so an upload that was attached to a record since the
+// scan, or that another instance is removing, is left alone.
+func removeUpload(ctx context.Context, upload models.Upload, cutoff time.Time) (bool, error) {
+	removed := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked []models.Upload
+		err := orphanedUploads(tx, cutoff).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("uploads.id = ?", upload.ID).
+			Find(&locked).Error
+		if err != nil || len(locked) == 0 {
+			return err
+		}
+
+		var renditions []models.ImageRendition
+		if err := tx.Where("source_path = ?", upload.Key).Find(&renditions).Error; err != nil {
+			return err
+		}
+		for _, r := range renditions {
+			if err := Files.Delete(ctx, r.Path); err != nil {
+				return err
+			}
+		}
+		if err := Files.Delete(ctx, upload.Key); err != nil {
+			return err
+		}
+
+		if err := tx.Where("source_path = ?", upload.Key).Delete(&models.ImageRendition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Upload{}, upload.ID).Error; err != nil {
+			return err
+		}
+		removed = true
+		return nil
+	})
+	return removed, err
+}
+
+// StartUploadCleanup removes orphaned uploads periodically until the process
+// exits.
+func StartUploadCleanup() {
+	go func() {
+		ticker := time.NewTicker(uploadCleanupInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			report, err := CleanupOrphanedUploads(context.Background(), UploadGracePeriod(), false)
+			if err != nil {
+				log.Printf("Upload cleanup error: %v", err)
+				continue
+			}
+			if report.Count > 0 {
+				log.Printf("Upload cleanup removed %d files (%d bytes)", report.Count, report.Bytes)
+			}
+		}
+	}()
+}
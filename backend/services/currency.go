@@ -0,0 +1,231 @@
+package services
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Currencies whose minor unit is not the usual cent.
+var minorUnits = map[string]int{
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+	"CLP": 0, "ISK": 0, "JPY": 0, "KRW": 0, "VND": 0,
+}
+
+// BaseCurrency is the currency exchange rates are quoted against.
+func BaseCurrency() string {
+	if base := NormalizeCurrency(os.Getenv("BASE_CURRENCY")); base != "" {
+		return base
+	}
+	return "USD"
+}
+
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func ValidCurrency(code string) bool {
+	return currencyPattern.MatchString(code)
+}
+
+// MinorUnits returns the number of decimal places used by a currency.
+func MinorUnits(code string) int {
+	if n, ok := minorUnits[code]; ok {
+		return n
+	}
+	return 2
+}
+
+// CheckCurrencyRate reports ErrUnknownCurrency unless prices stored in the
+// currency can be converted, that is it is the base currency or has an
+// exchange rate. The rate is locked so it cannot be deleted before tx ends.
+func CheckCurrencyRate(tx *gorm.DB, currency string) error {
+	if currency == BaseCurrency() {
+		return nil
+	}
+
+	var rate models.ExchangeRate
+	err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("currency = ?", currency).First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUnknownCurrency
+	}
+	return err
+}
+
+// BasePriceSQL is a SQL expression for the price of a row of table, in minor
+// units of the base currency at the stored exchange rates, so prices kept in
+// different currencies can be compared. It is NULL when a row's currency has
+// no rate.
+func BasePriceSQL(table string) string {
+	codes := make([]string, 0, len(minorUnits))
+	for code := range minorUnits {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	units := "CASE " + table + ".currency"
+	for _, code := range codes {
+		units += fmt.Sprintf(" WHEN '%s' THEN %d", code, minorUnits[code])
+	}
+	units += " ELSE 2 END"
+
+	base := BaseCurrency()
+	rate := fmt.Sprintf("CASE WHEN %[1]s.currency = '%[2]s' THEN 1 ELSE "+
+		"(SELECT rate::numeric FROM exchange_rates WHERE exchange_rates.currency = %[1]s.currency) END",
+		table, strings.ReplaceAll(base, "'", "''"))
+	return fmt.Sprintf("ROUND(%s.price * POWER(10::numeric, %d - (%s)) / %s)", table, MinorUnits(base), units, rate)
+}
+
+// Rates maps a currency code to its rate against the base currency.
+type Rates map[string]float64
+
+func LoadRates() (Rates, error) {
+	var rows []models.ExchangeRate
+	if err := database.DB.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	rates := Rates{BaseCurrency(): 1}
+	for _, row := range rows {
+		rates[row.Currency] = row.Rate
+	}
+	return rates, nil
+}
+
+// Convert moves an amount in minor units from one currency to another.
+func (r Rates) Convert(amount int64, from, to string) (int64, error) {
+	if from == to {
+		return amount, nil
+	}
+
+	fromRate, ok := r[from]
+	if !ok || fromRate <= 0 {
+		return 0, ErrUnknownCurrency
+	}
+	toRate, ok := r[to]
+	if !ok || toRate <= 0 {
+		return 0, ErrUnknownCurrency
+	}
+
+	major := float64(amount) / math.Pow10(MinorUnits(from))
+	converted := major / fromRate * toRate
+	return int64(math.Round(converted * math.Pow10(MinorUnits(to)))), nil
+}
+
+// Pricer prices products in a single currency, preferring per-currency
+// overrides and falling back to converting the stored price. Price schedules
+// only change the stored price, which is why they are refused for products
+// with overrides or variant prices.
+type Pricer struct {
+	Currency  string
+	rates     Rates
+	overrides map[uint]int64
+}
+
+// NewPricer loads the rates and overrides needed to price the given products.
+// An empty currency leaves every product in the currency it is stored in.
+func NewPricer(currency string, productIDs []uint) (*Pricer, error) {
+	currency = NormalizeCurrency(currency)
+
+	rates, err := LoadRates()
+	if err != nil {
+		return nil, err
+	}
+	if currency != "" {
+		if _, ok := rates[currency]; !ok {
+			return nil, ErrUnknownCurrency
+		}
+	}
+
+	p := &Pricer{Currency: currency, rates: rates, overrides: map[uint]int64{}}
+	if currency == "" || len(productIDs) == 0 {
+		return p, nil
+	}
+
+	var prices []models.ProductPrice
+	err = database.DB.
+		Where("currency = ? AND product_id IN ?", currency, productIDs).
+		Find(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, price := range prices {
+		p.overrides[price.ProductID] = price.Amount
+	}
+	return p, nil
+}
+
+// Price returns the product's price and the currency it is expressed in.
+func (p *Pricer) Price(product models.Product) (int64, string, error) {
+	if p.Currency == "" || p.Currency == product.Currency {
+		return product.Price, product.Currency, nil
+	}
+	if amount, ok := p.overrides[product.ID]; ok {
+		return amount, p.Currency, nil
+	}
+
+	amount, err := p.rates.Convert(product.Price, product.Currency, p.Currency)
+	if err != nil {
+		return 0, "", err
+	}
+	return amount, p.Currency, nil
+}
+
+// VariantPrice prices a variant, falling back to its product's price when the
+// variant has no price of its own.
+func (p *Pricer) VariantPrice(product models.Product, variant models.ProductVariant) (int64, string, error) {
+	if variant.Price == nil {
+		return p.Price(product)
+	}
+	return p.Convert(*variant.Price, product.Currency)
+}
+
+// Localize rewrites a product's price in place for a response. The product
+// must not be saved afterwards.
+func (p *Pricer) Localize(product *models.Product) error {
+	amount, currency, err := p.Price(*product)
+	if err != nil {
+		return err
+	}
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		if variant.Price == nil {
+			continue
+		}
+		price, _, err := p.Convert(*variant.Price, product.Currency)
+		if err != nil {
+			return err
+		}
+		variant.Price = &price
+	}
+
+	product.Price = amount
+	product.Currency = currency
+	return nil
+}
+
+// Convert is exposed for amounts that are not product prices, such as
+// order totals captured at checkout.
+func (p *Pricer) Convert(amount int64, from string) (int64, string, error) {
+	if p.Currency == "" || p.Currency == from {
+		return amount, from, nil
+	}
+	converted, err := p.rates.Convert(amount, from, p.Currency)
+	if err != nil {
+		return 0, "", err
+	}
+	return converted, p.Currency, nil
+}
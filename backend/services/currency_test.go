@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRatesConvert(t *testing.T) {
+	rates := Rates{"USD": 1, "EUR": 0.5, "JPY": 150, "KWD": 0.3}
+
+	tests := []struct {
+		amount   int64
+		from, to string
+		want     int64
+	}{
+		{1000, "USD", "USD", 1000},
+		{1000, "USD", "EUR", 500},
+		{500, "EUR", "USD", 1000},
+		// JPY has no minor unit: $10.00 is ¥1500.
+		{1000, "USD", "JPY", 1500},
+		{1500, "JPY", "USD", 1000},
+		// KWD has three decimals: $10.00 is 3.000 KWD.
+		{1000, "USD", "KWD", 3000},
+		// Rounded to the nearest minor unit.
+		{1, "USD", "EUR", 1},
+	}
+	for _, tt := range tests {
+		got, err := rates.Convert(tt.amount, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("Convert(%d, %s, %s): %v", tt.amount, tt.from, tt.to, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Convert(%d, %s, %s) = %d, want %d", tt.amount, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestRatesConvertUnknownCurrency(t *testing.T) {
+	rates := Rates{"USD": 1, "XXX": 0}
+
+	for _, pair := range [][2]string{{"USD", "GBP"}, {"GBP", "USD"}, {"USD", "XXX"}} {
+		if _, err := rates.Convert(100, pair[0], pair[1]); !errors.Is(err, ErrUnknownCurrency) {
+			t.Errorf("Convert(%s, %s) error = %v, want ErrUnknownCurrency", pair[0], pair[1], err)
+		}
+	}
+}
+
+func TestCheckCurrencyRate(t *testing.T) {
+	t.Setenv("BASE_CURRENCY", "USD")
+	const query = `SELECT \* FROM "exchange_rates" WHERE currency = \$1 ORDER BY "exchange_rates"."id" LIMIT \$2 FOR SHARE`
+
+	tests := []struct {
+		currency string
+		rows     *sqlmock.Rows
+		want     error
+	}{
+		{"USD", nil, nil},
+		{"EUR", sqlmock.NewRows([]string{"id", "currency", "rate"}).AddRow(1, "EUR", 0.9), nil},
+		{"GBP", sqlmock.NewRows([]string{"id", "currency", "rate"}), ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		db, mock := newMockDB(t)
+		if tt.rows != nil {
+			mock.ExpectQuery(query).WithArgs(tt.currency, 1).WillReturnRows(tt.rows)
+		}
+
+		if err := CheckCurrencyRate(db, tt.currency); !errors.Is(err, tt.want) {
+			t.Errorf("CheckCurrencyRate(%s) = %v, want %v", tt.currency, err, tt.want)
+		}
+	}
+}
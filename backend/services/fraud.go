@@ -0,0 +1,173 @@
+package services
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	FraudRuleUserVelocity    = "user_velocity"
+	FraudRuleIPVelocity      = "ip_velocity"
+	FraudRuleHighValueFirst  = "high_value_first_order"
+	FraudRuleAddressMismatch = "address_mismatch"
+	FraudRuleFailedPayments  = "failed_payments"
+)
+
+var defaultFraudRules = []models.FraudRule{
+	{
+		Code:          FraudRuleUserVelocity,
+		Description:   "User already placed Threshold orders within the window",
+		Enabled:       true,
+		Threshold:     5,
+		WindowMinutes: 60,
+		Score:         40,
+	},
+	{
+		Code:          FraudRuleIPVelocity,
+		Description:   "IP address already placed Threshold orders within the window",
+		Enabled:       true,
+		Threshold:     10,
+		WindowMinutes: 60,
+		Score:         40,
+	},
+	{
+		Code:        FraudRuleHighValueFirst,
+		Description: "First order worth at least Threshold in the base currency",
+		Enabled:     true,
+		Threshold:   1000,
+		Score:       30,
+	},
+	{
+		Code:        FraudRuleAddressMismatch,
+		Description: "Billing and shipping countries differ",
+		Enabled:     true,
+		Score:       20,
+	},
+	{
+		Code:          FraudRuleFailedPayments,
+		Description:   "User had Threshold failed payments within the window",
+		Enabled:       true,
+		Threshold:     3,
+		WindowMinutes: 24 * 60,
+		Score:         40,
+	},
+}
+
+// FraudHoldScore is the total score at which an order is held for review.
+func FraudHoldScore() int {
+	if score, err := strconv.Atoi(os.Getenv("FRAUD_HOLD_SCORE")); err == nil && score > 0 {
+		return score
+	}
+	return 50
+}
+
+// EnsureFraudRules inserts any built-in rule that is missing, leaving rules
+// an admin has already tuned untouched.
+func EnsureFraudRules() error {
+	rules := append([]models.FraudRule{}, defaultFraudRules...)
+	return database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&rules).Error
+}
+
+// ScreenOrder scores a new order against the enabled rules. It must run in
+// the checkout transaction before the order is inserted.
+func ScreenOrder(tx *gorm.DB, order *models.Order) (models.FraudCheck, error) {
+	var rules []models.FraudRule
+	if err := tx.Where("enabled = ?", true).Find(&rules).Error; err != nil {
+		return models.FraudCheck{}, err
+	}
+
+	check := models.FraudCheck{}
+	for _, rule := range rules {
+		hit, detail, err := evaluateFraudRule(tx, rule, order)
+		if err != nil {
+			return models.FraudCheck{}, err
+		}
+		if !hit {
+			continue
+		}
+		check.Score += rule.Score
+		check.Hits = append(check.Hits, models.FraudHit{Rule: rule.Code, Score: rule.Score, Detail: detail})
+	}
+
+	check.Held = check.Score >= FraudHoldScore()
+	return check, nil
+}
+
+func evaluateFraudRule(tx *gorm.DB, rule models.FraudRule, order *models.Order) (bool, string, error) {
+	since := time.Now().Add(-time.Duration(rule.WindowMinutes) * time.Minute)
+
+	switch rule.Code {
+	case FraudRuleUserVelocity:
+		var count int64
+		err := tx.Model(&models.Order{}).
+			Where("user_id = ? AND created_at >= ?", order.UserID, since).
+			Count(&count).Error
+		return float64(count) >= rule.Threshold,
+			fmt.Sprintf("%d orders in the last %d minutes", count, rule.WindowMinutes), err
+
+	case FraudRuleIPVelocity:
+		if order.IPAddress == "" {
+			return false, "", nil
+		}
+		var count int64
+		err := tx.Model(&models.Order{}).
+			Where("ip_address = ? AND created_at >= ?", order.IPAddress, since).
+			Count(&count).Error
+		return float64(count) >= rule.Threshold,
+			fmt.Sprintf("%d orders from %s in the last %d minutes", count, order.IPAddress, rule.WindowMinutes), err
+
+	case FraudRuleHighValueFirst:
+		var previous int64
+		err := tx.Model(&models.Order{}).
+			Where("user_id = ? AND status <> ?", order.UserID, models.OrderStatusCancelled).
+			Count(&previous).Error
+		if err != nil || previous > 0 {
+			return false, "", err
+		}
+
+		rates, err := LoadRates()
+		if err != nil {
+			return false, "", err
+		}
+		base := BaseCurrency()
+		total, err := rates.Convert(order.Total, order.Currency, base)
+		if errors.Is(err, ErrUnknownCurrency) {
+			// Without a rate the order's value is unknown, so the rule is
+			// skipped rather than failing checkout.
+			return false, "", nil
+		}
+		if err != nil {
+			return false, "", err
+		}
+		value := float64(total) / math.Pow10(MinorUnits(base))
+		return value >= rule.Threshold, fmt.Sprintf("first order worth %.2f %s", value, base), nil
+
+	case FraudRuleAddressMismatch:
+		billing := strings.ToUpper(order.BillingCountry)
+		shipping := strings.ToUpper(order.ShippingCountry)
+		if billing == "" || shipping == "" || billing == shipping {
+			return false, "", nil
+		}
+		return true, fmt.Sprintf("billing %s, shipping %s", billing, shipping), nil
+
+	case FraudRuleFailedPayments:
+		var count int64
+		err := tx.Model(&models.Order{}).
+			Where("user_id = ? AND status = ? AND updated_at >= ?", order.UserID, models.OrderStatusPaymentFailed, since).
+			Count(&count).Error
+		return float64(count) >= rule.Threshold,
+			fmt.Sprintf("%d failed payments in the last %d minutes", count, rule.WindowMinutes), err
+	}
+
+	return false, "", nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// SyncPrimaryImage makes sure a product with images has exactly one primary
+// image and mirrors its path into the legacy Product.Image field.
+func SyncPrimaryImage(tx *gorm.DB, productID uint) error {
+	var images []models.ProductImage
+	if err := tx.Where("product_id = ?", productID).Order("is_primary DESC, sort_order, id").Find(&images).Error; err != nil {
+		return err
+	}
+
+	path := ""
+	if len(images) > 0 {
+		primary := images[0]
+		path = primary.Path
+		err := tx.Model(&models.ProductImage{}).
+			Where("product_id = ?", productID).
+			Update("is_primary", gorm.Expr("id = ?", primary.ID)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&models.Product{}).Where("id = ?", productID).UpdateColumn("image", path).Error
+}
+
+// SetPrimaryImagePath is used when a client sets Product.Image directly: the
+// path becomes the primary gallery image, added to the gallery if needed.
+func SetPrimaryImagePath(tx *gorm.DB, product models.Product) error {
+	if product.Image == "" {
+		return nil
+	}
+
+	var image models.ProductImage
+	err := tx.Where("product_id = ? AND path = ?", product.ID, product.Image).First(&image).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		image = models.ProductImage{ProductID: product.ID, Path: product.Image, AltText: product.Title}
+		tx.Model(&models.ProductImage{}).
+			Where("product_id = ?", product.ID).
+			Select("COALESCE(MAX(sort_order) + 1, 0)").
+			Scan(&image.SortOrder)
+		err = tx.Create(&image).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&models.ProductImage{}).
+		Where("product_id = ?", product.ID).
+		Update("is_primary", gorm.Expr("id = ?", image.ID)).Error
+	if err != nil {
+		return err
+	}
+	return SyncPrimaryImage(tx, product.ID)
+}
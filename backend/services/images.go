@@ -0,0 +1,298 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"ecommerce/backend/models"
+	"encoding/binary"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var ErrInvalidImage = errors.New("file is not a supported image")
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+const (
+	maxImageSide   = 10000
+	maxImagePixels = 40_000_000
+	jpegQuality    = 85
+)
+
+// ImageSize is a rendition generated for every uploaded image. Renditions are
+// never upscaled past the original.
+type ImageSize struct {
+	Name    string
+	MaxSide int
+}
+
+var ImageSizes = []ImageSize{
+	{Name: "thumbnail", MaxSide: 200},
+	{Name: "medium", MaxSide: 600},
+	{Name: "large", MaxSide: 1200},
+}
+
+// ProcessedImage is an upload after decoding: the metadata-free original and
+// its renditions, ready to be written out.
+type ProcessedImage struct {
+	Original   EncodedImage
+	Renditions []EncodedImage
+}
+
+type EncodedImage struct {
+	Size   string
+	Format string
+	Ext    string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// ProcessImage decodes an upload, rejects anything that is not a reasonably
+// sized image, applies the EXIF orientation and re-encodes it. Re-encoding
+// drops EXIF and any other embedded metadata.
+func ProcessImage(data []byte) (ProcessedImage, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ProcessedImage{}, ErrInvalidImage
+	}
+	if config.Width > maxImageSide || config.Height > maxImageSide ||
+		config.Width*config.Height > maxImagePixels {
+		return ProcessedImage{}, ErrImageTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ProcessedImage{}, ErrInvalidImage
+	}
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	// Keep transparency where the source may have it; everything else is JPEG.
+	encode, outFormat, ext := encodeJPEG, "jpeg", ".jpg"
+	if format != "jpeg" && !opaque(img) {
+		encode, outFormat, ext = encodePNG, "png", ".png"
+	}
+
+	original, err := encode(img)
+	if err != nil {
+		return ProcessedImage{}, err
+	}
+	bounds := img.Bounds()
+	processed := ProcessedImage{Original: EncodedImage{
+		Format: outFormat, Ext: ext, Width: bounds.Dx(), Height: bounds.Dy(), Data: original,
+	}}
+
+	for _, size := range ImageSizes {
+		resized := resizeToFit(img, size.MaxSide)
+		b := resized.Bounds()
+
+		encoded, err := encode(resized)
+		if err != nil {
+			return ProcessedImage{}, err
+		}
+		processed.Renditions = append(processed.Renditions, EncodedImage{
+			Size: size.Name, Format: outFormat, Ext: ext, Width: b.Dx(), Height: b.Dy(), Data: encoded,
+		})
+
+		var webp bytes.Buffer
+		if err := nativewebp.Encode(&webp, resized, nil); err == nil {
+			processed.Renditions = append(processed.Renditions, EncodedImage{
+				Size: size.Name, Format: "webp", Ext: ".webp", Width: b.Dx(), Height: b.Dy(), Data: webp.Bytes(),
+			})
+		}
+	}
+
+	return processed, nil
+}
+
+// WriteProcessedImage saves an image and its renditions to Files next to each
+// other under prefix, using base as the shared file name stem. It returns the
+// key of the original and the renditions to record against it.
+func WriteProcessedImage(ctx context.Context, processed ProcessedImage, prefix, base string) (string, []models.ImageRendition, error) {
+	originalKey := path.Join(prefix, base+processed.Original.Ext)
+	if err := Files.Put(ctx, originalKey, processed.Original.Data, "image/"+processed.Original.Format); err != nil {
+		return "", nil, err
+	}
+
+	var renditions []models.ImageRendition
+	for _, r := range processed.Renditions {
+		key := path.Join(prefix, base+"_"+r.Size+r.Ext)
+		if err := Files.Put(ctx, key, r.Data, "image/"+r.Format); err != nil {
+			return "", nil, err
+		}
+		renditions = append(renditions, models.ImageRendition{
+			SourcePath: originalKey,
+			Size:       r.Size,
+			Format:     r.Format,
+			Path:       key,
+			Width:      r.Width,
+			Height:     r.Height,
+		})
+	}
+	return originalKey, renditions, nil
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	return buf.Bytes(), err
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	err := (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+	return buf.Bytes(), err
+}
+
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+func resizeToFit(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+
+	if w >= h {
+		h = h * maxSide / w
+		w = maxSide
+	} else {
+		w = w * maxSide / h
+		h = maxSide
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Over, nil)
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag (1-8) from a JPEG, returning
+// 1 when there is none. Only the APP1 segment is parsed.
+func jpegOrientation(data []byte) int {
+	r := bytes.NewReader(data)
+	var marker [2]byte
+	if _, err := io.ReadFull(r, marker[:]); err != nil || marker != [2]byte{0xFF, 0xD8} {
+		return 1
+	}
+
+	for {
+		if _, err := io.ReadFull(r, marker[:]); err != nil || marker[0] != 0xFF {
+			return 1
+		}
+		var length uint16
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil || length < 2 {
+			return 1
+		}
+		segment := make([]byte, length-2)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return 1
+		}
+
+		switch marker[1] {
+		case 0xE1:
+			if bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+				return exifOrientation(segment[6:])
+			}
+		case 0xDA:
+			// Start of scan: no more metadata segments follow.
+			return 1
+		}
+	}
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation rotates and flips img so it displays upright once the
+// EXIF orientation tag has been stripped.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	swap := orientation >= 5
+	dw, dh := w, h
+	if swap {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
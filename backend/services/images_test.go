@@ -0,0 +1,60 @@
+package services
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// tiffWithTags builds a minimal TIFF header and first IFD holding the given
+// SHORT tags, as found in a JPEG's EXIF segment.
+func tiffWithTags(order binary.ByteOrder, tags map[uint16]uint16) []byte {
+	tiff := make([]byte, 8, 8+2+12*len(tags))
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+
+	count := make([]byte, 2)
+	order.PutUint16(count, uint16(len(tags)))
+	tiff = append(tiff, count...)
+	for tag, value := range tags {
+		entry := make([]byte, 12)
+		order.PutUint16(entry[0:], tag)
+		order.PutUint16(entry[2:], 3) // SHORT
+		order.PutUint32(entry[4:], 1)
+		order.PutUint16(entry[8:], value)
+		tiff = append(tiff, entry...)
+	}
+	return tiff
+}
+
+func TestExifOrientation(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for want := 1; want <= 8; want++ {
+			tiff := tiffWithTags(order, map[uint16]uint16{0x0112: uint16(want)})
+			if got := exifOrientation(tiff); got != want {
+				t.Errorf("%v: orientation = %d, want %d", order, got, want)
+			}
+		}
+	}
+}
+
+func TestExifOrientationDefaults(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":             nil,
+		"short":             []byte("II*\x00"),
+		"bad byte order":    []byte("XX*\x00\x08\x00\x00\x00\x00\x00"),
+		"offset past end":   []byte("II*\x00\xff\x00\x00\x00"),
+		"no orientation":    tiffWithTags(binary.LittleEndian, map[uint16]uint16{0x010F: 5}),
+		"invalid value":     tiffWithTags(binary.LittleEndian, map[uint16]uint16{0x0112: 9}),
+		"truncated entries": tiffWithTags(binary.LittleEndian, map[uint16]uint16{0x0112: 6})[:16],
+	}
+	for name, tiff := range tests {
+		if got := exifOrientation(tiff); got != 1 {
+			t.Errorf("%s: orientation = %d, want 1", name, got)
+		}
+	}
+}
@@ -0,0 +1,511 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"ecommerce/backend/models"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ImportCSV  = "csv"
+	ImportJSON = "json"
+
+	DefaultImportBatchSize = 500
+)
+
+var ErrUnsupportedImportFormat = errors.New("import format must be csv or json")
+
+// errImportRollback ends an import transaction without reporting a failure.
+var errImportRollback = errors.New("import rolled back")
+
+// importColumns are the product fields an import can set. Only sku is
+// required; a column left out of the file leaves that field unchanged on
+// existing products.
+var importColumns = map[string]bool{
+	"sku":         true,
+	"title":       true,
+	"slug":        true,
+	"description": true,
+	"price":       true,
+	"currency":    true,
+	"category":    true,
+	"category_id": true,
+	"stock":       true,
+	"image":       true,
+	"attributes":  true,
+	"status":      true,
+	"publish_at":  true,
+}
+
+// ImportRow is one product from an import file. Row counts data rows from 1,
+// so in a CSV file it is the line number minus the header.
+type ImportRow struct {
+	Row    int
+	Fields map[string]string
+	err    error
+}
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+type ImportOptions struct {
+	// BatchSize commits every BatchSize rows separately, skipping rows that
+	// fail. Zero imports the whole file in one transaction that is rolled
+	// back if any row fails.
+	BatchSize int
+	// Start is the first row to import, to resume a batched import.
+	Start  int
+	DryRun bool
+	// ChangedBy is the admin recorded in the price history.
+	ChangedBy *uint
+}
+
+// ImportReport describes an import. In atomic mode, or on a dry run, the
+// created and updated counts are what the file would do; Committed says
+// whether it was written.
+type ImportReport struct {
+	Mode      string `json:"mode"`
+	DryRun    bool   `json:"dry_run"`
+	Rows      int    `json:"rows"`
+	Processed int    `json:"processed"`
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Failed    int    `json:"failed"`
+	Committed bool   `json:"committed"`
+	// NextRow is where to resume a batched import that stopped early.
+	NextRow int              `json:"next_row,omitempty"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+// ParseImport reads an import file in the given format.
+func ParseImport(format string, r io.Reader) ([]ImportRow, error) {
+	switch format {
+	case ImportCSV:
+		return parseImportCSV(r)
+	case ImportJSON:
+		return parseImportJSON(r)
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+}
+
+// parseImportCSV reads a CSV file with a header row naming the columns.
+func parseImportCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !importColumns[name] {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("column %q appears twice", name)
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+	if !seen["sku"] {
+		return nil, errors.New("the sku column is required")
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := ImportRow{Row: len(rows) + 1, Fields: map[string]string{}}
+		if len(record) != len(columns) {
+			row.err = fmt.Errorf("expected %d fields, found %d", len(columns), len(record))
+		}
+		for i, value := range record {
+			if i < len(columns) {
+				row.Fields[columns[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseImportJSON reads a JSON array of product objects keyed like the CSV
+// columns. attributes is a nested object.
+func parseImportJSON(r io.Reader) ([]ImportRow, error) {
+	var objects []map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, errors.New("the file must be a JSON array of objects")
+	}
+
+	rows := make([]ImportRow, len(objects))
+	for i, object := range objects {
+		row := ImportRow{Row: i + 1, Fields: map[string]string{}}
+		for name, raw := range object {
+			value, err := importJSONValue(name, raw)
+			if err != nil && row.err == nil {
+				row.err = err
+			}
+			row.Fields[name] = value
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+func importJSONValue(name string, raw json.RawMessage) (string, error) {
+	if !importColumns[name] {
+		return "", fmt.Errorf("unknown field %q", name)
+	}
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case bytes.Equal(raw, []byte("null")):
+		return "", nil
+	case len(raw) > 0 && raw[0] == '"':
+		var text string
+		err := json.Unmarshal(raw, &text)
+		return text, err
+	case len(raw) > 0 && raw[0] == '{' && name == "attributes":
+		return string(raw), nil
+	default:
+		var number json.Number
+		if err := json.Unmarshal(raw, &number); err != nil {
+			return "", fmt.Errorf("%s must be a string or a number", name)
+		}
+		return number.String(), nil
+	}
+}
+
+// ImportProducts creates or updates products by SKU. Rows that fail are
+// listed in the report; only database failures outside a row return an
+// error, along with the report so far.
+func ImportProducts(ctx context.Context, db *gorm.DB, rows []ImportRow, opts ImportOptions) (ImportReport, error) {
+	report := ImportReport{Mode: "atomic", DryRun: opts.DryRun, Rows: len(rows), Errors: []ImportRowError{}}
+	if opts.BatchSize > 0 {
+		report.Mode = "batch"
+	}
+	if opts.Start < 1 {
+		opts.Start = 1
+	}
+	duplicates := duplicateSKUs(rows)
+
+	pending := []ImportRow{}
+	for _, row := range rows {
+		if row.Row >= opts.Start {
+			pending = append(pending, row)
+		}
+	}
+
+	if opts.BatchSize <= 0 {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := importBatch(ctx, tx, pending, duplicates, opts, &report); err != nil {
+				return err
+			}
+			if report.Failed > 0 || opts.DryRun {
+				return errImportRollback
+			}
+			return nil
+		})
+		if err != nil && err != errImportRollback {
+			return report, err
+		}
+		report.Committed = err == nil
+		return report, nil
+	}
+
+	for start := 0; start < len(pending); start += opts.BatchSize {
+		end := start + opts.BatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+
+		// Counts are only kept for batches that were written.
+		attempt := report
+		attempt.Errors = append([]ImportRowError{}, report.Errors...)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := importBatch(ctx, tx, batch, duplicates, opts, &attempt); err != nil {
+				return err
+			}
+			if opts.DryRun {
+				return errImportRollback
+			}
+			return nil
+		})
+		if err != nil && err != errImportRollback {
+			report.NextRow = batch[0].Row
+			return report, err
+		}
+		report = attempt
+	}
+	report.Committed = !opts.DryRun
+	return report, nil
+}
+
+// duplicateSKUs maps each row whose SKU already appeared earlier in the file
+// to the first row with that SKU.
+func duplicateSKUs(rows []ImportRow) map[int]int {
+	first := map[string]int{}
+	duplicates := map[int]int{}
+	for _, row := range rows {
+		sku := strings.TrimSpace(row.Fields["sku"])
+		if sku == "" {
+			continue
+		}
+		if at, seen := first[sku]; seen {
+			duplicates[row.Row] = at
+		} else {
+			first[sku] = row.Row
+		}
+	}
+	return duplicates
+}
+
+func importBatch(ctx context.Context, tx *gorm.DB, rows []ImportRow, duplicates map[int]int, opts ImportOptions, report *ImportReport) error {
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var created bool
+		err := row.err
+		if err == nil {
+			if at, ok := duplicates[row.Row]; ok {
+				err = fmt.Errorf("sku already appears in row %d", at)
+			}
+		}
+		if err == nil {
+			// Each row gets a savepoint so a failed row leaves the rest of
+			// the transaction usable.
+			err = tx.Transaction(func(tx *gorm.DB) error {
+				var err error
+				created, err = importRow(tx, row, opts.ChangedBy)
+				return err
+			})
+		}
+
+		report.Processed++
+		switch {
+		case err != nil:
+			report.Failed++
+			report.Errors = append(report.Errors, ImportRowError{
+				Row:   row.Row,
+				SKU:   strings.TrimSpace(row.Fields["sku"]),
+				Error: err.Error(),
+			})
+		case created:
+			report.Created++
+		default:
+			report.Updated++
+		}
+	}
+	return nil
+}
+
+// importRow creates or updates the product with the row's SKU and reports
+// whether it was created.
+func importRow(tx *gorm.DB, row ImportRow, changedBy *uint) (bool, error) {
+	field := func(name string) (string, bool) {
+		value, ok := row.Fields[name]
+		return strings.TrimSpace(value), ok
+	}
+
+	sku, _ := field("sku")
+	if sku == "" {
+		return false, errors.New("sku is required")
+	}
+
+	var product models.Product
+	err := tx.Unscoped().Where("sku = ?", sku).First(&product).Error
+	exists := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if exists && product.DeletedAt.Valid {
+		return false, errors.New("the product with this sku is in the trash; restore it first")
+	}
+	if !exists {
+		taken, err := SKUTaken(tx, sku, 0, 0)
+		if err != nil {
+			return false, err
+		}
+		if taken {
+			return false, errors.New("the sku belongs to a product variant")
+		}
+	}
+	product.SKU = &sku
+	previousPrice, previousCurrency := product.Price, product.Currency
+	previousTitle := product.Title
+	wasPublished := exists && IsPublished(product, time.Now())
+
+	if title, ok := field("title"); ok {
+		if title == "" {
+			return false, errors.New("title cannot be empty")
+		}
+		product.Title = title
+	}
+	if !exists && product.Title == "" {
+		return false, errors.New("title is required")
+	}
+	if description, ok := field("description"); ok {
+		product.Description = description
+	}
+
+	if value, _ := field("price"); value != "" {
+		price, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || price < 0 {
+			return false, errors.New("price must be a non-negative integer in minor units")
+		}
+		product.Price = price
+	} else if !exists {
+		return false, errors.New("price is required")
+	}
+
+	// A blank currency keeps the product's own; new products default to
+	// the base currency.
+	if currency, _ := field("currency"); currency != "" {
+		product.Currency = currency
+	}
+	product.Currency = NormalizeCurrency(product.Currency)
+	if product.Currency == "" {
+		product.Currency = BaseCurrency()
+	}
+	if !ValidCurrency(product.Currency) {
+		return false, errors.New("invalid currency code")
+	}
+	if product.Currency != previousCurrency {
+		err := CheckCurrencyRate(tx, product.Currency)
+		if errors.Is(err, ErrUnknownCurrency) {
+			return false, fmt.Errorf("no exchange rate for %s", product.Currency)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	categoryID, hasCategoryID := field("category_id")
+	category, hasCategory := field("category")
+	switch {
+	case categoryID != "":
+		id, err := strconv.ParseUint(categoryID, 10, 64)
+		if err != nil {
+			return false, errors.New("category_id must be an integer")
+		}
+		value := uint(id)
+		product.CategoryID = &value
+	case category != "":
+		product.Category = category
+		product.CategoryID = nil
+	case hasCategory || hasCategoryID:
+		product.Category = ""
+		product.CategoryID = nil
+	}
+
+	if value, ok := field("stock"); ok {
+		if value == "" {
+			product.Stock = nil
+		} else {
+			stock, err := strconv.Atoi(value)
+			if err != nil || stock < 0 {
+				return false, errors.New("stock must be a non-negative integer")
+			}
+			product.Stock = &stock
+		}
+	}
+	if image, ok := field("image"); ok {
+		product.Image = image
+	}
+	if value, ok := field("attributes"); ok {
+		attributes := models.ProductAttributes{}
+		if value != "" {
+			if err := json.Unmarshal([]byte(value), &attributes); err != nil {
+				return false, errors.New("attributes must be a JSON object")
+			}
+		}
+		product.Attributes = attributes
+	}
+	// A blank status leaves the product as it is, like a blank price.
+	if status, _ := field("status"); status != "" {
+		product.Status = status
+	}
+	if value, ok := field("publish_at"); ok {
+		if value == "" {
+			product.PublishAt = nil
+		} else {
+			publishAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return false, errors.New("publish_at must be an RFC 3339 time")
+			}
+			product.PublishAt = &publishAt
+		}
+	}
+	if err := ApplyProductStatus(&product); err != nil {
+		return false, err
+	}
+
+	if err := ApplyProductCategory(tx, &product); err != nil {
+		return false, err
+	}
+	if err := ApplyProductAttributes(tx, &product); err != nil {
+		return false, err
+	}
+	if slug, _ := field("slug"); !exists || slug != "" || product.Title != previousTitle {
+		if err := ApplyProductSlug(tx, &product, slug); err != nil {
+			return false, err
+		}
+	}
+
+	if exists {
+		err = tx.Omit(clause.Associations, "RatingAverage", "ReviewCount").Save(&product).Error
+	} else {
+		err = tx.Omit(clause.Associations).Create(&product).Error
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := SetPrimaryImagePath(tx, product); err != nil {
+		return false, err
+	}
+	if !exists || product.Price != previousPrice || product.Currency != previousCurrency {
+		if err := RecordPrice(tx, product, models.PriceSourceImport, nil, changedBy); err != nil {
+			return false, err
+		}
+	}
+
+	event := EventProductUpdated
+	if !exists {
+		event = EventProductCreated
+	}
+	if err := RecordEvent(tx, event, product); err != nil {
+		return false, err
+	}
+	return !exists, RecordVisibilityChange(tx, wasPublished, product)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	file := "\ufeffSKU, Title ,price\nA-1,Shoe,1999\nA-2,\"Boot, tall\",2999\nA-3,Short\n"
+
+	rows, err := ParseImport(ImportCSV, strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ParseImport: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	if rows[0].Row != 1 || rows[0].Fields["sku"] != "A-1" || rows[0].Fields["title"] != "Shoe" || rows[0].Fields["price"] != "1999" {
+		t.Errorf("row 1 = %+v", rows[0])
+	}
+	if rows[1].Fields["title"] != "Boot, tall" {
+		t.Errorf("row 2 title = %q, want %q", rows[1].Fields["title"], "Boot, tall")
+	}
+	if rows[0].err != nil || rows[1].err != nil {
+		t.Errorf("complete rows have errors: %v, %v", rows[0].err, rows[1].err)
+	}
+	if rows[2].err == nil {
+		t.Error("row with a missing field has no error")
+	}
+}
+
+func TestParseImportCSVHeaderErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"unknown column": "sku,colour\n",
+		"repeated":       "sku,title,Title\n",
+		"no sku":         "title,price\n",
+	}
+	for name, file := range tests {
+		if _, err := ParseImport(ImportCSV, strings.NewReader(file)); err == nil {
+			t.Errorf("%s: ParseImport succeeded, want an error", name)
+		}
+	}
+}
+
+func TestParseImportJSON(t *testing.T) {
+	file := `[
+		{"sku": "A-1", "title": "Shoe", "price": 1999, "stock": null, "attributes": {"size": 42}},
+		{"sku": "A-2", "colour": "red"}
+	]`
+
+	rows, err := ParseImport(ImportJSON, strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ParseImport: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	fields := rows[0].Fields
+	if fields["sku"] != "A-1" || fields["price"] != "1999" || fields["stock"] != "" || fields["attributes"] != `{"size": 42}` {
+		t.Errorf("row 1 fields = %v", fields)
+	}
+	if rows[0].err != nil {
+		t.Errorf("row 1 error = %v", rows[0].err)
+	}
+	if rows[1].err == nil {
+		t.Error("row with an unknown field has no error")
+	}
+}
+
+func TestParseImportJSONRejectsNonArray(t *testing.T) {
+	if _, err := ParseImport(ImportJSON, strings.NewReader(`{"sku": "A-1"}`)); err == nil {
+		t.Error("ParseImport accepted an object")
+	}
+}
+
+func TestParseImportUnknownFormat(t *testing.T) {
+	if _, err := ParseImport("xml", strings.NewReader("")); !errors.Is(err, ErrUnsupportedImportFormat) {
+		t.Errorf("error = %v, want ErrUnsupportedImportFormat", err)
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrOutOfStock = errors.New("out of stock")
+
+// stockRow is the row an order draws stock from: its variant when the
+// variant tracks its own stock, otherwise the product. Trashed products are
+// included so cancelling an order still returns its units.
+func stockRow(tx *gorm.DB, order *models.Order) (*gorm.DB, error) {
+	if order.VariantID != nil {
+		var variant models.ProductVariant
+		err := tx.Select("id", "stock").First(&variant, *order.VariantID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		// A deleted variant matches no row: it cannot be bought and its
+		// units are not returned to the product.
+		if err != nil || variant.Stock != nil {
+			return tx.Model(&models.ProductVariant{}).Where("id = ?", *order.VariantID), nil
+		}
+	}
+	return tx.Unscoped().Model(&models.Product{}).Where("id = ?", order.ProductID), nil
+}
+
+// ReserveStock takes the order's quantity from tracked stock. Rows without
+// tracked stock are always available.
+func ReserveStock(tx *gorm.DB, order *models.Order) error {
+	row, err := stockRow(tx, order)
+	if err != nil {
+		return err
+	}
+	result := row.
+		Where("(stock IS NULL OR stock >= ?)", order.Quantity).
+		UpdateColumn("stock", gorm.Expr("CASE WHEN stock IS NULL THEN NULL ELSE stock - ? END", order.Quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOutOfStock
+	}
+	return nil
+}
+
+// ReleaseStock returns units from a cancelled order. It must run only once
+// per order: SetOrderStatus guarantees that, and cancelled orders are final.
+func ReleaseStock(tx *gorm.DB, order *models.Order) error {
+	row, err := stockRow(tx, order)
+	if err != nil {
+		return err
+	}
+	return row.
+		Where("stock IS NOT NULL").
+		UpdateColumn("stock", gorm.Expr("stock + ?", order.Quantity)).Error
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const FlagLink = "link"
+
+// linkPattern matches URLs and bare domains such as "example.com/deal".
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|info|biz|io|co|me|ly|xyz|ru|cn|shop|store|site|online|app|dev)\b`)
+
+// AutoApprove reports whether content that passes screening is published
+// straight away. Set MODERATION_AUTO_APPROVE=false to hold everything for
+// an admin. REVIEW_AUTO_APPROVE, its name from when only reviews were
+// moderated, is still read when the new name is unset.
+func AutoApprove() bool {
+	value, ok := os.LookupEnv("MODERATION_AUTO_APPROVE")
+	if !ok {
+		value = os.Getenv("REVIEW_AUTO_APPROVE")
+	}
+	return value != "false"
+}
+
+// ScreenContent checks text against the banned-word list and for links and
+// sets the content's status: flagged content waits in the moderation queue.
+// Trusted authors, such as admins, are approved with their flags recorded.
+func ScreenContent(tx *gorm.DB, m *models.Moderation, text string, trusted bool) error {
+	var words []string
+	if err := tx.Model(&models.BannedWord{}).Pluck("word", &words).Error; err != nil {
+		return err
+	}
+
+	flags := pq.StringArray{}
+	for _, word := range words {
+		if containsWord(text, word) {
+			flags = append(flags, "banned_word:"+word)
+		}
+	}
+	if linkPattern.MatchString(text) {
+		flags = append(flags, FlagLink)
+	}
+
+	m.Flags = flags
+	m.Status = models.ModerationPending
+	if trusted || (len(flags) == 0 && AutoApprove()) {
+		m.Status = models.ModerationApproved
+	}
+	m.ModerationNote = ""
+	m.ModeratedBy = nil
+	m.ModeratedAt = nil
+	return nil
+}
+
+func ScreenReview(tx *gorm.DB, review *models.Review) error {
+	return ScreenContent(tx, &review.Moderation, review.Title+"\n"+review.Body, false)
+}
+
+// containsWord matches a banned word or phrase case-insensitively on word
+// boundaries, so "ass" does not match "class".
+func containsWord(text, word string) bool {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return false
+	}
+	pattern := `(?i)(?:^|\W)` + regexp.QuoteMeta(word) + `(?:\W|$)`
+	matched, _ := regexp.MatchString(pattern, text)
+	return matched
+}
+
+// decide records an admin's decision on moderated content.
+func decide(tx *gorm.DB, model interface{}, m *models.Moderation, decision, note string, moderator uint) error {
+	now := time.Now()
+	m.Status = decision
+	m.ModerationNote = note
+	m.ModeratedBy = &moderator
+	m.ModeratedAt = &now
+
+	return tx.Model(model).Updates(map[string]interface{}{
+		"status":          m.Status,
+		"moderation_note": m.ModerationNote,
+		"moderated_by":    m.ModeratedBy,
+		"moderated_at":    m.ModeratedAt,
+	}).Error
+}
+
+func rejectionNotice(what, note string) string {
+	body := "Your " + what + " was not published."
+	if note != "" {
+		body += " Reason: " + note
+	}
+	return body
+}
+
+// ModerateReview records an admin's decision on a review and keeps the
+// product's rating in step. Rejected authors are notified.
+func ModerateReview(tx *gorm.DB, review *models.Review, decision, note string, moderator uint) error {
+	if err := decide(tx, review, &review.Moderation, decision, note, moderator); err != nil {
+		return err
+	}
+	if err := RefreshProductRating(tx, review.ProductID); err != nil {
+		return err
+	}
+	if decision != models.ModerationRejected {
+		return nil
+	}
+
+	body := rejectionNotice(fmt.Sprintf("review %q", review.Title), note)
+	if err := Notify(tx, review.UserID, NotificationReviewRejected, "Your review was rejected", body); err != nil {
+		return err
+	}
+	return RecordEvent(tx, EventReviewRejected, review)
+}
+
+func ModerateQuestion(tx *gorm.DB, question *models.ProductQuestion, decision, note string, moderator uint) error {
+	if err := decide(tx, question, &question.Moderation, decision, note, moderator); err != nil {
+		return err
+	}
+	if decision != models.ModerationRejected {
+		return nil
+	}
+	return Notify(tx, question.UserID, NotificationQuestionRejected, "Your question was rejected",
+		rejectionNotice("question", note))
+}
+
+// ModerateAnswer records a decision on an answer. Approving it tells the
+// asker their question was answered.
+func ModerateAnswer(tx *gorm.DB, answer *models.ProductAnswer, decision, note string, moderator uint) error {
+	if err := decide(tx, answer, &answer.Moderation, decision, note, moderator); err != nil {
+		return err
+	}
+	if decision == models.ModerationApproved {
+		return NotifyAnswered(tx, answer)
+	}
+	return Notify(tx, answer.UserID, NotificationAnswerRejected, "Your answer was rejected",
+		rejectionNotice("answer", note))
+}
+
+// NotifyAnswered tells the asker that an approved answer was posted to their
+// question, unless they answered it themselves.
+func NotifyAnswered(tx *gorm.DB, answer *models.ProductAnswer) error {
+	var question models.ProductQuestion
+	if err := tx.First(&question, answer.QuestionID).Error; err != nil {
+		return err
+	}
+	if question.UserID == answer.UserID {
+		return nil
+	}
+	return Notify(tx, question.UserID, NotificationQuestionAnswered, "Your question was answered",
+		fmt.Sprintf("Someone answered your question %q.", question.Body))
+}
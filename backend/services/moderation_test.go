@@ -0,0 +1,91 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectBannedWords(mock sqlmock.Sqlmock, words ...string) {
+	rows := sqlmock.NewRows([]string{"word"})
+	for _, word := range words {
+		rows.AddRow(word)
+	}
+	mock.ExpectQuery(`SELECT "word" FROM "banned_words"`).WillReturnRows(rows)
+}
+
+func TestScreenContent(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		trusted    bool
+		wantStatus string
+		wantFlags  []string
+	}{
+		{"clean", "Great shoes, very comfy", false, models.ModerationApproved, nil},
+		{"banned word", "These are Rubbish shoes", false, models.ModerationPending, []string{"banned_word:rubbish"}},
+		{"word inside another", "Rubbishy but fine", false, models.ModerationApproved, nil},
+		{"phrase", "a total scam deal", false, models.ModerationPending, []string{"banned_word:scam deal"}},
+		{"link", "Cheaper at example.com/deal", false, models.ModerationPending, []string{FlagLink}},
+		{"trusted", "Rubbish, see www.example.com", true, models.ModerationApproved, []string{"banned_word:rubbish", FlagLink}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			expectBannedWords(mock, "rubbish", "scam deal", " ")
+
+			moderated := time.Now()
+			m := models.Moderation{ModerationNote: "old note", ModeratedBy: new(uint), ModeratedAt: &moderated}
+			if err := ScreenContent(db, &m, tt.text, tt.trusted); err != nil {
+				t.Fatalf("ScreenContent: %v", err)
+			}
+
+			if m.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", m.Status, tt.wantStatus)
+			}
+			if len(m.Flags) != len(tt.wantFlags) {
+				t.Fatalf("flags = %v, want %v", m.Flags, tt.wantFlags)
+			}
+			for i := range tt.wantFlags {
+				if m.Flags[i] != tt.wantFlags[i] {
+					t.Errorf("flags = %v, want %v", m.Flags, tt.wantFlags)
+				}
+			}
+			if m.ModerationNote != "" || m.ModeratedBy != nil || m.ModeratedAt != nil {
+				t.Errorf("earlier decision was kept: %+v", m)
+			}
+		})
+	}
+}
+
+func TestScreenContentWithoutAutoApprove(t *testing.T) {
+	t.Setenv("MODERATION_AUTO_APPROVE", "false")
+	db, mock := newMockDB(t)
+	expectBannedWords(mock)
+
+	var m models.Moderation
+	if err := ScreenContent(db, &m, "Great shoes", false); err != nil {
+		t.Fatalf("ScreenContent: %v", err)
+	}
+	if m.Status != models.ModerationPending {
+		t.Errorf("status = %q, want pending", m.Status)
+	}
+}
+
+func TestAutoApproveFallsBackToOldName(t *testing.T) {
+	// Setenv restores the variable afterwards; it is then unset for the test.
+	t.Setenv("MODERATION_AUTO_APPROVE", "")
+	os.Unsetenv("MODERATION_AUTO_APPROVE")
+	t.Setenv("REVIEW_AUTO_APPROVE", "false")
+	if AutoApprove() {
+		t.Error("REVIEW_AUTO_APPROVE=false ignored when MODERATION_AUTO_APPROVE is unset")
+	}
+
+	t.Setenv("MODERATION_AUTO_APPROVE", "true")
+	if !AutoApprove() {
+		t.Error("MODERATION_AUTO_APPROVE does not take precedence")
+	}
+}
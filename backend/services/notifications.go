@@ -0,0 +1,25 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	NotificationReviewRejected   = "review_rejected"
+	NotificationQuestionRejected = "question_rejected"
+	NotificationAnswerRejected   = "answer_rejected"
+	NotificationQuestionAnswered = "question_answered"
+)
+
+// Notify leaves an in-app notification for a user. It runs in the caller's
+// transaction so the notification only exists if the change behind it does.
+func Notify(tx *gorm.DB, userID uint, notificationType, title, body string) error {
+	return tx.Create(&models.Notification{
+		UserID: userID,
+		Type:   notificationType,
+		Title:  title,
+		Body:   body,
+	}).Error
+}
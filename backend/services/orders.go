@@ -0,0 +1,43 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidOrderTransition = errors.New("order cannot move to that status")
+	ErrOrderStatusChanged     = errors.New("order status changed concurrently")
+)
+
+// SetOrderStatus moves an order to a new status if the transition is
+// allowed. Cancelling returns the order's stock and paying records
+// order.paid. The update only applies if the order still has the status it
+// was loaded with, so two requests cannot both cancel it and return its
+// stock twice.
+func SetOrderStatus(tx *gorm.DB, order *models.Order, status string) error {
+	if !models.CanTransitionOrder(order.Status, status) {
+		return ErrInvalidOrderTransition
+	}
+
+	result := tx.Model(&models.Order{}).
+		Where("id = ? AND status = ?", order.ID, order.Status).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOrderStatusChanged
+	}
+	order.Status = status
+
+	switch status {
+	case models.OrderStatusCancelled:
+		return ReleaseStock(tx, order)
+	case models.OrderStatusPaid:
+		return RecordEvent(tx, EventOrderPaid, *order)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderPaid          = "order.paid"
+	EventProductCreated     = "product.created"
+	EventProductUpdated     = "product.updated"
+	EventProductDeleted     = "product.deleted"
+	EventProductRestored    = "product.restored"
+	EventProductPublished   = "product.published"
+	EventProductUnpublished = "product.unpublished"
+	EventReviewRejected     = "review.rejected"
+)
+
+const (
+	outboxMaxAttempts  = 20
+	outboxBaseBackoff  = 5 * time.Second
+	outboxMaxBackoff   = time.Hour
+	outboxPollInterval = time.Second
+	outboxBatchSize    = 50
+	// outboxLease is how long a claimed event is left to its dispatcher
+	// before another instance may pick it up.
+	outboxLease = 5 * time.Minute
+)
+
+// EventHandler receives an outbox event. Delivery is at-least-once, so
+// handlers must tolerate seeing the same event ID twice.
+type EventHandler func(event models.OutboxEvent) error
+
+var (
+	subscribersMu sync.RWMutex
+	subscribers   = map[string][]EventHandler{}
+)
+
+// Subscribe registers a handler for an event type, or "*" for every event.
+func Subscribe(eventType string, handler EventHandler) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscribers[eventType] = append(subscribers[eventType], handler)
+}
+
+func handlersFor(eventType string) []EventHandler {
+	subscribersMu.RLock()
+	defer subscribersMu.RUnlock()
+
+	handlers := append([]EventHandler{}, subscribers[eventType]...)
+	return append(handlers, subscribers["*"]...)
+}
+
+// RecordEvent writes an event to the outbox using the caller's transaction.
+func RecordEvent(tx *gorm.DB, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	event := models.OutboxEvent{
+		Type:          eventType,
+		Payload:       string(payload),
+		NextAttemptAt: time.Now(),
+	}
+	return tx.Create(&event).Error
+}
+
+// RetryEvent puts a failed event back in the queue.
+func RetryEvent(event *models.OutboxEvent) error {
+	return database.DB.Model(event).Updates(map[string]interface{}{
+		"attempts":        0,
+		"failed_at":       nil,
+		"last_error":      "",
+		"next_attempt_at": time.Now(),
+	}).Error
+}
+
+// StartOutboxDispatcher publishes pending events until the process exits.
+func StartOutboxDispatcher() {
+	go func() {
+		ticker := time.NewTicker(outboxPollInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := dispatchOutbox(); err != nil {
+				log.Printf("Outbox dispatcher error: %v", err)
+			}
+		}
+	}()
+}
+
+// dispatchOutbox publishes due events. They are claimed in a short
+// transaction and handlers run outside it, so a slow handler holds no row
+// locks; each outcome is saved once its handlers have finished.
+func dispatchOutbox() error {
+	events, err := claimOutboxEvents()
+	if err != nil {
+		return err
+	}
+
+	for i := range events {
+		publishEvent(&events[i])
+		if err := database.DB.Save(&events[i]).Error; err != nil {
+			log.Printf("Outbox event %d: failed to record outcome: %v", events[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// claimOutboxEvents leases due events to this dispatcher by moving their
+// next attempt past the lease, so another instance does not publish them
+// concurrently.
+func claimOutboxEvents() ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Order("id").
+			Limit(outboxBatchSize).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+		return tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			UpdateColumn("next_attempt_at", time.Now().Add(outboxLease)).Error
+	})
+	return events, err
+}
+
+func publishEvent(event *models.OutboxEvent) {
+	event.Attempts++
+
+	for _, handler := range handlersFor(event.Type) {
+		if err := runHandler(handler, *event); err != nil {
+			event.LastError = err.Error()
+			if event.Attempts >= outboxMaxAttempts {
+				now := time.Now()
+				event.FailedAt = &now
+				log.Printf("Outbox event %d (%s) failed permanently: %v", event.ID, event.Type, err)
+				return
+			}
+			event.NextAttemptAt = time.Now().Add(outboxBackoff(event.Attempts))
+			return
+		}
+	}
+
+	now := time.Now()
+	event.PublishedAt = &now
+	event.LastError = ""
+}
+
+// runHandler keeps a panicking subscriber from taking the dispatcher down.
+func runHandler(handler EventHandler, event models.OutboxEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(event)
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	wait := outboxBaseBackoff << uint(attempts-1)
+	if wait <= 0 || wait > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return wait
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const priceScheduleInterval = time.Minute
+
+var ErrPriceScheduleOverlap = errors.New("price schedule overlaps another one for this product")
+
+var ErrPriceScheduleOverridden = errors.New("product has per-currency or variant prices")
+
+// CheckPriceSchedulable rejects schedules for products whose price is not
+// only the product row's. A schedule changes that one price, but the Pricer
+// prefers per-currency prices and variants with their own price, so a sale
+// would not reach customers who see those.
+func CheckPriceSchedulable(tx *gorm.DB, productID uint) error {
+	var overrides int64
+	if err := tx.Model(&models.ProductPrice{}).Where("product_id = ?", productID).Count(&overrides).Error; err != nil {
+		return err
+	}
+	var pricedVariants int64
+	if err := tx.Model(&models.ProductVariant{}).Where("product_id = ? AND price IS NOT NULL", productID).Count(&pricedVariants).Error; err != nil {
+		return err
+	}
+	if overrides > 0 || pricedVariants > 0 {
+		return ErrPriceScheduleOverridden
+	}
+	return nil
+}
+
+var ErrPriceScheduled = errors.New("product has a pending or running price schedule")
+
+// CheckPriceOverridable is the other half of CheckPriceSchedulable: it
+// rejects per-currency and variant prices while a schedule is pending or
+// running for the product. The product row is locked, as when a schedule is
+// created, so the two checks cannot both pass concurrently.
+func CheckPriceOverridable(tx *gorm.DB, productID uint) error {
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "SHARE"}).Select("id").First(&models.Product{}, productID).Error; err != nil {
+		return err
+	}
+	var schedules int64
+	err := tx.Model(&models.PriceSchedule{}).
+		Where("product_id = ? AND status IN ?", productID, []string{models.PriceScheduleScheduled, models.PriceScheduleActive}).
+		Count(&schedules).Error
+	if err != nil {
+		return err
+	}
+	if schedules > 0 {
+		return ErrPriceScheduled
+	}
+	return nil
+}
+
+// RecordPrice adds the product's current price to its history.
+func RecordPrice(tx *gorm.DB, product models.Product, source string, scheduleID, changedBy *uint) error {
+	return tx.Create(&models.PriceChange{
+		ProductID:  product.ID,
+		Price:      product.Price,
+		Currency:   product.Currency,
+		Source:     source,
+		ScheduleID: scheduleID,
+		ChangedBy:  changedBy,
+		ChangedAt:  time.Now(),
+	}).Error
+}
+
+// CheckPriceScheduleOverlap rejects a schedule that would be in force at the
+// same time as another pending or running one. A permanent change is an
+// instant, so it only clashes with a sale running at that moment or another
+// change at the same time.
+func CheckPriceScheduleOverlap(tx *gorm.DB, schedule models.PriceSchedule) error {
+	query := tx.Model(&models.PriceSchedule{}).
+		Where("product_id = ? AND status IN ?", schedule.ProductID,
+			[]string{models.PriceScheduleScheduled, models.PriceScheduleActive})
+
+	if schedule.EndsAt == nil {
+		query = query.Where(
+			"(ends_at IS NULL AND starts_at = ?) OR (ends_at IS NOT NULL AND starts_at <= ? AND ends_at > ?)",
+			schedule.StartsAt, schedule.StartsAt, schedule.StartsAt)
+	} else {
+		query = query.Where(
+			"(ends_at IS NULL AND starts_at >= ? AND starts_at < ?) OR (ends_at IS NOT NULL AND starts_at < ? AND ends_at > ?)",
+			schedule.StartsAt, *schedule.EndsAt, *schedule.EndsAt, schedule.StartsAt)
+	}
+
+	var overlapping int64
+	if err := query.Count(&overlapping).Error; err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrPriceScheduleOverlap
+	}
+	return nil
+}
+
+// ApplyDuePriceSchedules starts schedules whose time has come and ends sales
+// that are over. Each schedule is handled in its own transaction and skipped
+// if another instance holds it.
+func ApplyDuePriceSchedules(now time.Time) error {
+	var due []uint
+	err := database.DB.Model(&models.PriceSchedule{}).
+		Where("(status = ? AND starts_at <= ?) OR (status = ? AND ends_at <= ?)",
+			models.PriceScheduleScheduled, now, models.PriceScheduleActive, now).
+		Order("starts_at, id").
+		Pluck("id", &due).Error
+	if err != nil {
+		return err
+	}
+
+	for _, id := range due {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			var schedule models.PriceSchedule
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND status IN ?", id, []string{models.PriceScheduleScheduled, models.PriceScheduleActive}).
+				First(&schedule).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			if schedule.Status == models.PriceScheduleScheduled {
+				return startPriceSchedule(tx, &schedule, now)
+			}
+			return EndPriceSchedule(tx, &schedule, models.PriceScheduleCompleted, now)
+		})
+		if err != nil {
+			log.Printf("Price schedule %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func startPriceSchedule(tx *gorm.DB, schedule *models.PriceSchedule, now time.Time) error {
+	schedule.AppliedAt = &now
+
+	// A sale that was missed entirely, e.g. while the server was down, is not
+	// applied late.
+	if schedule.EndsAt != nil && !schedule.EndsAt.After(now) {
+		schedule.Status = models.PriceScheduleCompleted
+		schedule.EndedAt = &now
+		schedule.Outcome = "The sale ended before it could be applied"
+		return tx.Save(schedule).Error
+	}
+
+	var product models.Product
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, schedule.ProductID).Error; err != nil {
+		return err
+	}
+
+	// Prices are only meaningful in the currency they were set in.
+	if schedule.Currency == "" {
+		schedule.Currency = product.Currency
+	}
+	if product.Currency != schedule.Currency {
+		schedule.Status = models.PriceScheduleCompleted
+		schedule.EndedAt = &now
+		schedule.Outcome = "The product's currency changed, so the price was not applied"
+		return tx.Save(schedule).Error
+	}
+
+	original := product.Price
+	schedule.OriginalPrice = &original
+	schedule.Status = models.PriceScheduleActive
+	if schedule.EndsAt == nil {
+		schedule.Status = models.PriceScheduleCompleted
+		schedule.EndedAt = &now
+	}
+	if err := tx.Save(schedule).Error; err != nil {
+		return err
+	}
+
+	return setScheduledPrice(tx, product, schedule.Price, models.PriceSourceScheduleStart, schedule.ID)
+}
+
+// EndPriceSchedule finishes a schedule with the given status. A running sale
+// restores the price it replaced, unless the price or currency was changed by
+// hand in the meantime, in which case that price is kept.
+func EndPriceSchedule(tx *gorm.DB, schedule *models.PriceSchedule, status string, now time.Time) error {
+	wasActive := schedule.Status == models.PriceScheduleActive
+	schedule.Status = status
+	schedule.EndedAt = &now
+	if !wasActive {
+		return tx.Save(schedule).Error
+	}
+
+	var product models.Product
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, schedule.ProductID).Error; err != nil {
+		return err
+	}
+
+	changed := product.Price != schedule.Price || (schedule.Currency != "" && product.Currency != schedule.Currency)
+	if changed || schedule.OriginalPrice == nil {
+		schedule.Outcome = "The price was changed during the sale, so it was not restored"
+		return tx.Save(schedule).Error
+	}
+	if err := tx.Save(schedule).Error; err != nil {
+		return err
+	}
+	return setScheduledPrice(tx, product, *schedule.OriginalPrice, models.PriceSourceScheduleEnd, schedule.ID)
+}
+
+func setScheduledPrice(tx *gorm.DB, product models.Product, price int64, source string, scheduleID uint) error {
+	if product.Price == price {
+		return nil
+	}
+	product.Price = price
+	if err := tx.Unscoped().Model(&product).UpdateColumn("price", price).Error; err != nil {
+		return err
+	}
+	if err := RecordPrice(tx, product, source, &scheduleID, nil); err != nil {
+		return err
+	}
+	return RecordEvent(tx, EventProductUpdated, product)
+}
+
+// StartPriceScheduler applies due price schedules every minute until the
+// process exits.
+func StartPriceScheduler() {
+	go func() {
+		ticker := time.NewTicker(priceScheduleInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := ApplyDuePriceSchedules(time.Now()); err != nil {
+				log.Printf("Price scheduler error: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"database/sql/driver"
+	"ecommerce/backend/models"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const (
+	countPriceSchedules = `SELECT count\(\*\) FROM "price_schedules" WHERE \(product_id = \$1 AND status IN \(\$2,\$3\)\) AND `
+	permanentOverlap    = countPriceSchedules + `\(\(ends_at IS NULL AND starts_at = \$4\) OR`
+	saleOverlap         = countPriceSchedules + `\(\(ends_at IS NULL AND starts_at >= \$4 AND starts_at < \$5\) OR`
+)
+
+func TestCheckPriceScheduleOverlap(t *testing.T) {
+	start := time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC)
+	end := start.Add(72 * time.Hour)
+
+	tests := []struct {
+		name     string
+		schedule models.PriceSchedule
+		query    string
+		args     []driver.Value
+		count    int64
+		want     error
+	}{
+		{
+			name:     "free permanent change",
+			schedule: models.PriceSchedule{ProductID: 7, StartsAt: start},
+			query:    permanentOverlap,
+			args:     []driver.Value{7, models.PriceScheduleScheduled, models.PriceScheduleActive, start, start, start},
+		},
+		{
+			name:     "permanent change during a sale",
+			schedule: models.PriceSchedule{ProductID: 7, StartsAt: start},
+			query:    permanentOverlap,
+			args:     []driver.Value{7, models.PriceScheduleScheduled, models.PriceScheduleActive, start, start, start},
+			count:    1,
+			want:     ErrPriceScheduleOverlap,
+		},
+		{
+			name:     "free sale",
+			schedule: models.PriceSchedule{ProductID: 7, StartsAt: start, EndsAt: &end},
+			query:    saleOverlap,
+			args:     []driver.Value{7, models.PriceScheduleScheduled, models.PriceScheduleActive, start, end, end, start},
+		},
+		{
+			name:     "overlapping sale",
+			schedule: models.PriceSchedule{ProductID: 7, StartsAt: start, EndsAt: &end},
+			query:    saleOverlap,
+			args:     []driver.Value{7, models.PriceScheduleScheduled, models.PriceScheduleActive, start, end, end, start},
+			count:    2,
+			want:     ErrPriceScheduleOverlap,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(tt.query).
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.count))
+
+			if err := CheckPriceScheduleOverlap(db, tt.schedule); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPriceSchedulable(t *testing.T) {
+	tests := []struct {
+		name              string
+		overrides, priced int64
+		want              error
+	}{
+		{"plain product", 0, 0, nil},
+		{"currency overrides", 1, 0, ErrPriceScheduleOverridden},
+		{"variant prices", 0, 3, ErrPriceScheduleOverridden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(`SELECT count\(\*\) FROM "product_prices" WHERE product_id = \$1`).
+				WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.overrides))
+			mock.ExpectQuery(`SELECT count\(\*\) FROM "product_variants" WHERE product_id = \$1 AND price IS NOT NULL`).
+				WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.priced))
+
+			if err := CheckPriceSchedulable(db, 7); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPriceOverridable(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedules int64
+		want      error
+	}{
+		{"no schedules", 0, nil},
+		{"pending or running schedule", 1, ErrPriceScheduled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectQuery(`SELECT "id" FROM "products" WHERE "products"."id" = \$1 ORDER BY "products"."id" LIMIT \$2 FOR SHARE`).
+				WithArgs(7, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+			mock.ExpectQuery(`SELECT count\(\*\) FROM "price_schedules" WHERE product_id = \$1 AND status IN \(\$2,\$3\)`).
+				WithArgs(7, models.PriceScheduleScheduled, models.PriceScheduleActive).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.schedules))
+
+			if err := CheckPriceOverridable(db, 7); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"ecommerce/backend/utils"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSKUTaken  = errors.New("sku already in use")
+	ErrSlugTaken = errors.New("slug already in use")
+)
+
+// ApplyProductSKU trims the product's SKU, clearing it when blank, and checks
+// that no other product or variant has it.
+func ApplyProductSKU(tx *gorm.DB, product *models.Product) error {
+	if product.SKU == nil {
+		return nil
+	}
+	sku := strings.TrimSpace(*product.SKU)
+	if sku == "" {
+		product.SKU = nil
+		return nil
+	}
+	product.SKU = &sku
+
+	taken, err := SKUTaken(tx, sku, product.ID, 0)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrSKUTaken
+	}
+	return nil
+}
+
+// SKUTaken reports whether a product or variant other than the given ones
+// uses sku. Products and variants share one SKU space, and trashed products
+// keep their SKU.
+func SKUTaken(tx *gorm.DB, sku string, productID, variantID uint) (bool, error) {
+	var products int64
+	err := tx.Unscoped().Model(&models.Product{}).Where("sku = ? AND id <> ?", sku, productID).Count(&products).Error
+	if err != nil {
+		return false, err
+	}
+	var variants int64
+	err = tx.Model(&models.ProductVariant{}).Where("sku = ? AND id <> ?", sku, variantID).Count(&variants).Error
+	return products+variants > 0, err
+}
+
+// ApplyProductSlug gives the product the requested slug, or one made from its
+// title when requested is empty, numbered if another product already uses
+// it. The slug the product had before is kept as a redirect so old links
+// keep working.
+func ApplyProductSlug(tx *gorm.DB, product *models.Product, requested string) error {
+	base := utils.ResourceSlug(product.Title, "product")
+	if utils.Slugify(requested) != "" {
+		base = utils.ResourceSlug(requested, "product")
+	}
+
+	// Slugs of trashed products and other products' redirects stay reserved.
+	var used, redirected []string
+	err := tx.Unscoped().Model(&models.Product{}).
+		Where("id <> ? AND (slug = ? OR slug LIKE ?)", product.ID, base, base+"-%").
+		Pluck("slug", &used).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Model(&models.ProductSlugRedirect{}).
+		Where("product_id <> ? AND (slug = ? OR slug LIKE ?)", product.ID, base, base+"-%").
+		Pluck("slug", &redirected).Error
+	if err != nil {
+		return err
+	}
+	taken := map[string]bool{}
+	for _, slug := range append(used, redirected...) {
+		taken[slug] = true
+	}
+
+	slug := base
+	if utils.Slugify(requested) == "" {
+		slug = utils.UniqueSlug(base, func(s string) bool { return taken[s] })
+	} else if taken[slug] {
+		return ErrSlugTaken
+	}
+	if slug == product.Slug {
+		return nil
+	}
+
+	if product.ID != 0 {
+		// Going back to an earlier slug retires its redirect.
+		if err := tx.Where("product_id = ? AND slug = ?", product.ID, slug).Delete(&models.ProductSlugRedirect{}).Error; err != nil {
+			return err
+		}
+		if product.Slug != "" {
+			if err := tx.Create(&models.ProductSlugRedirect{Slug: product.Slug, ProductID: product.ID}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	product.Slug = slug
+	return nil
+}
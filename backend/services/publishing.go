@@ -0,0 +1,127 @@
+package services
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const publishInterval = time.Minute
+
+var ErrInvalidProductStatus = errors.New("status must be one of draft, published, archived")
+
+// PublishedSQL is the condition for a product being public, for the products
+// table under the given name or alias. Drafts count from their publish time,
+// so they appear on time even before the publish job has run.
+func PublishedSQL(table string) string {
+	return fmt.Sprintf("(%[1]s.status = '%[2]s' OR (%[1]s.status = '%[3]s' AND %[1]s.publish_at <= NOW()))",
+		table, models.ProductStatusPublished, models.ProductStatusDraft)
+}
+
+// PublishedProducts is a query scope that hides drafts and archived products.
+func PublishedProducts(db *gorm.DB) *gorm.DB {
+	return db.Where(PublishedSQL("products"))
+}
+
+// IsPublished reports whether the public can see the product.
+func IsPublished(product models.Product, now time.Time) bool {
+	switch product.Status {
+	case models.ProductStatusPublished:
+		return true
+	case models.ProductStatusDraft:
+		return product.PublishAt != nil && !product.PublishAt.After(now)
+	}
+	return false
+}
+
+// ApplyProductStatus checks a product's status before it is saved. Products
+// saved without a status are published, as they were before statuses
+// existed, unless they come with a publish time, which makes them drafts
+// until then. A publish time only applies to drafts, so it is cleared for any
+// other status, and a draft whose time has already come is published now.
+func ApplyProductStatus(product *models.Product) error {
+	if product.Status == "" {
+		product.Status = models.ProductStatusPublished
+		if product.PublishAt != nil {
+			product.Status = models.ProductStatusDraft
+		}
+	}
+	switch product.Status {
+	case models.ProductStatusDraft:
+		if product.PublishAt != nil && !product.PublishAt.After(time.Now()) {
+			product.Status = models.ProductStatusPublished
+			product.PublishAt = nil
+		}
+	case models.ProductStatusPublished, models.ProductStatusArchived:
+		product.PublishAt = nil
+	default:
+		return ErrInvalidProductStatus
+	}
+	return nil
+}
+
+// RecordVisibilityChange emits product.published or product.unpublished when
+// a save changed whether the product is public.
+func RecordVisibilityChange(tx *gorm.DB, wasPublished bool, product models.Product) error {
+	isPublished := IsPublished(product, time.Now())
+	switch {
+	case isPublished && !wasPublished:
+		return RecordEvent(tx, EventProductPublished, product)
+	case wasPublished && !isPublished:
+		return RecordEvent(tx, EventProductUnpublished, product)
+	}
+	return nil
+}
+
+// PublishDueProducts publishes drafts whose publish time has passed.
+func PublishDueProducts(now time.Time) (int, error) {
+	published := 0
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var products []models.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND publish_at <= ?", models.ProductStatusDraft, now).
+			Find(&products).Error
+		if err != nil {
+			return err
+		}
+
+		for _, product := range products {
+			product.Status = models.ProductStatusPublished
+			product.PublishAt = nil
+			err := tx.Model(&product).
+				Updates(map[string]interface{}{"status": product.Status, "publish_at": nil}).Error
+			if err != nil {
+				return err
+			}
+			// The product was already visible from its publish time, so
+			// this only settles its status; the event marks the moment.
+			if err := RecordEvent(tx, EventProductPublished, product); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	return published, err
+}
+
+// StartPublishScheduler publishes scheduled drafts every minute until the
+// process exits.
+func StartPublishScheduler() {
+	go func() {
+		ticker := time.NewTicker(publishInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := PublishDueProducts(time.Now()); err != nil {
+				log.Printf("Publish scheduler error: %v", err)
+			}
+		}
+	}()
+}
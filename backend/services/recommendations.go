@@ -0,0 +1,190 @@
+package services
+
+import (
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	recommendationInterval = 6 * time.Hour
+
+	// associationLockKey keeps instances from rebuilding associations at the
+	// same time.
+	associationLockKey = 740047
+
+	ReasonBoughtTogether = "bought_together"
+	ReasonBestSeller     = "best_seller"
+)
+
+// SoldOrderStatuses are the order states that count as a purchase.
+var SoldOrderStatuses = []string{
+	models.OrderStatusPaid,
+	models.OrderStatusShipped,
+	models.OrderStatusDelivered,
+}
+
+// BasketWindow is how close together a customer's orders must be to count
+// as bought together, from RECOMMENDATION_BASKET_HOURS (default 24). Orders
+// hold a single product, so this stands in for a shared basket.
+func BasketWindow() time.Duration {
+	return envHours("RECOMMENDATION_BASKET_HOURS", 24*time.Hour)
+}
+
+// RecommendationLookback limits associations and best sellers to recent
+// orders, from RECOMMENDATION_LOOKBACK_DAYS (default 365).
+func RecommendationLookback() time.Duration {
+	return envDays("RECOMMENDATION_LOOKBACK_DAYS", 365)
+}
+
+// MinAssociationCount is how many customers must have bought two products
+// together before they are recommended together, from
+// RECOMMENDATION_MIN_COUNT (default 2). Below that, best sellers are used.
+func MinAssociationCount() int64 {
+	if value, err := strconv.ParseInt(os.Getenv("RECOMMENDATION_MIN_COUNT"), 10, 64); err == nil && value > 0 {
+		return value
+	}
+	return 2
+}
+
+func envDays(name string, fallback int) time.Duration {
+	days, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || days <= 0 {
+		days = fallback
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// RefreshProductAssociations rebuilds the co-purchase counts from order
+// history. It returns false without doing anything if another instance is
+// already rebuilding them.
+func RefreshProductAssociations() (bool, error) {
+	refreshed := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", associationLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		if err := tx.Exec("DELETE FROM product_associations").Error; err != nil {
+			return err
+		}
+		since := time.Now().Add(-RecommendationLookback())
+		err := tx.Exec(`
+			INSERT INTO product_associations (product_id, related_product_id, count, computed_at)
+			SELECT a.product_id, b.product_id, COUNT(DISTINCT a.user_id), NOW()
+			FROM orders a
+			JOIN orders b ON b.user_id = a.user_id
+				AND b.product_id <> a.product_id
+				AND b.created_at BETWEEN a.created_at - ? * INTERVAL '1 second' AND a.created_at + ? * INTERVAL '1 second'
+			WHERE a.status IN ? AND b.status IN ?
+				AND a.created_at >= ? AND b.created_at >= ?
+			GROUP BY a.product_id, b.product_id
+			HAVING COUNT(DISTINCT a.user_id) >= ?`,
+			BasketWindow().Seconds(), BasketWindow().Seconds(),
+			SoldOrderStatuses, SoldOrderStatuses,
+			since, since,
+			MinAssociationCount()).Error
+		if err != nil {
+			return err
+		}
+		refreshed = true
+		return nil
+	})
+	return refreshed, err
+}
+
+// StartRecommendationJob rebuilds product associations at startup and then
+// periodically until the process exits.
+func StartRecommendationJob() {
+	go func() {
+		ticker := time.NewTicker(recommendationInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := RefreshProductAssociations(); err != nil {
+				log.Printf("Recommendation refresh error: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+type ScoredProduct struct {
+	ProductID uint
+	Score     int64
+	Reason    string
+}
+
+// Recommend picks up to limit products to suggest alongside the given ones:
+// first those most often bought with them, then best sellers from their
+// categories. Trashed and sold-out products are skipped.
+func Recommend(db *gorm.DB, productIDs []uint, limit int) ([]ScoredProduct, error) {
+	picked := []ScoredProduct{}
+	if len(productIDs) == 0 {
+		return picked, nil
+	}
+	exclude := append([]uint{}, productIDs...)
+
+	var associated []ScoredProduct
+	err := db.Table("product_associations").
+		Select("product_associations.related_product_id AS product_id, SUM(product_associations.count) AS score").
+		Joins("JOIN products ON products.id = product_associations.related_product_id").
+		Where("product_associations.product_id IN ? AND product_associations.related_product_id NOT IN ?", productIDs, exclude).
+		Where("products.deleted_at IS NULL AND (products.stock IS NULL OR products.stock > 0)").
+		Where(PublishedSQL("products")).
+		Group("product_associations.related_product_id").
+		Order("score DESC, product_associations.related_product_id DESC").
+		Limit(limit).
+		Scan(&associated).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range associated {
+		product.Reason = ReasonBoughtTogether
+		picked = append(picked, product)
+		exclude = append(exclude, product.ProductID)
+	}
+	if len(picked) >= limit {
+		return picked, nil
+	}
+
+	var categoryIDs []uint
+	err = db.Unscoped().Model(&models.Product{}).
+		Where("id IN ? AND category_id IS NOT NULL", productIDs).
+		Distinct().
+		Pluck("category_id", &categoryIDs).Error
+	if err != nil || len(categoryIDs) == 0 {
+		return picked, err
+	}
+
+	var bestSellers []ScoredProduct
+	err = db.Raw(`
+		SELECT p.id AS product_id, COALESCE(SUM(o.quantity), 0) AS score
+		FROM products p
+		LEFT JOIN orders o ON o.product_id = p.id AND o.status IN ? AND o.created_at >= ?
+		WHERE p.category_id IN ? AND p.id NOT IN ?
+			AND p.deleted_at IS NULL AND (p.stock IS NULL OR p.stock > 0)
+			AND `+PublishedSQL("p")+`
+		GROUP BY p.id
+		ORDER BY score DESC, p.id DESC
+		LIMIT ?`,
+		SoldOrderStatuses, time.Now().Add(-RecommendationLookback()),
+		categoryIDs, exclude, limit-len(picked)).Scan(&bestSellers).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range bestSellers {
+		product.Reason = ReasonBestSeller
+		picked = append(picked, product)
+	}
+	return picked, nil
+}
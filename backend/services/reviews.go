@@ -0,0 +1,59 @@
+package services
+
+import (
+	"ecommerce/backend/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrNotVerifiedBuyer = errors.New("only customers who received this product can review it")
+
+// DeliveredOrderID finds the customer's most recent delivered order for the
+// product, which is what entitles them to review it or answer questions
+// about it. Only admins can mark an order delivered, through
+// UpdateOrderStatus, so customers cannot verify themselves.
+func DeliveredOrderID(tx *gorm.DB, userID, productID uint) (uint, error) {
+	var order models.Order
+	err := tx.Select("id").
+		Where("user_id = ? AND product_id = ? AND status = ?", userID, productID, models.OrderStatusDelivered).
+		Order("id DESC").
+		First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrNotVerifiedBuyer
+	}
+	return order.ID, err
+}
+
+// RefreshProductRating recomputes the review aggregates stored on a product
+// from its approved reviews.
+func RefreshProductRating(tx *gorm.DB, productID uint) error {
+	return tx.Exec(`
+		UPDATE products SET
+			rating_average = COALESCE((SELECT ROUND(AVG(rating)::numeric, 2) FROM reviews WHERE product_id = ? AND status = ?), 0),
+			review_count = (SELECT COUNT(*) FROM reviews WHERE product_id = ? AND status = ?)
+		WHERE id = ?`,
+		productID, models.ReviewStatusApproved, productID, models.ReviewStatusApproved, productID).Error
+}
+
+// RatingDistribution counts a product's approved reviews per star rating.
+func RatingDistribution(tx *gorm.DB, productID uint) (map[int]int64, error) {
+	var rows []struct {
+		Rating int
+		Count  int64
+	}
+	err := tx.Model(&models.Review{}).
+		Select("rating, COUNT(*) AS count").
+		Where("product_id = ? AND status = ?", productID, models.ReviewStatusApproved).
+		Group("rating").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := map[int]int64{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+	for _, row := range rows {
+		distribution[row.Rating] = row.Count
+	}
+	return distribution, nil
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+var ErrInvalidStorageKey = errors.New("invalid storage key")
+
+// Storage holds uploaded files. Keys are slash-separated paths such as
+// "uploads/shoe.jpg"; they are what gets stored on records.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL returns an address clients can fetch the file from.
+	URL(ctx context.Context, key string) (string, error)
+}
+
+// Files is the storage uploads are written to. It defaults to the local disk
+// and is replaced by ConfigureStorage at startup.
+var Files Storage = &LocalStorage{Dir: "."}
+
+// ConfigureStorage picks the storage backend from STORAGE_DRIVER ("local" or
+// "s3") and its settings from the environment.
+func ConfigureStorage() error {
+	switch driver := strings.ToLower(os.Getenv("STORAGE_DRIVER")); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "."
+		}
+		Files = &LocalStorage{Dir: dir, BaseURL: os.Getenv("STORAGE_PUBLIC_URL")}
+		return nil
+	case "s3":
+		s3, err := NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			UseSSL:    os.Getenv("S3_USE_SSL") != "false",
+			PublicURL: os.Getenv("STORAGE_PUBLIC_URL"),
+			URLExpiry: envSeconds("S3_URL_EXPIRY_SECONDS", time.Hour),
+		})
+		if err != nil {
+			return err
+		}
+		Files = s3
+		return nil
+	default:
+		return errors.New("unknown STORAGE_DRIVER " + driver)
+	}
+}
+
+func envSeconds(name string, fallback time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(os.Getenv(name)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+// cleanKey normalizes a key and rejects ones that would escape the storage
+// root.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+key)), "/")
+	if key == "" || key == "." {
+		return "", ErrInvalidStorageKey
+	}
+	return key, nil
+}
+
+// LocalStorage keeps files on the local disk under Dir. Files are served by
+// the app itself, so BaseURL is usually left empty to produce root-relative
+// URLs.
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial upload.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(ctx context.Context, key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + key, nil
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// PublicURL, when set, is the base of a public (or CDN) address for the
+	// bucket and URLs are built from it. Otherwise URLs are presigned.
+	PublicURL string
+	URLExpiry time.Duration
+}
+
+// S3Storage keeps files in an S3-compatible bucket, such as AWS S3 or MinIO.
+type S3Storage struct {
+	client *minio.Client
+	config S3Config
+}
+
+// NewS3Storage connects to the bucket, creating it if it does not exist yet.
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, errors.New("S3_ENDPOINT and S3_BUCKET are required for s3 storage")
+	}
+	if config.Region == "" {
+		// Setting the region up front saves a lookup request when presigning.
+		config.Region = "us-east-1"
+	}
+
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	exists, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err := client.MakeBucket(ctx, config.Bucket, minio.MakeBucketOptions{Region: config.Region})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &S3Storage{client: client, config: config}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(ctx, s.config.Bucket, key, bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.config.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Storage) URL(ctx context.Context, key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if s.config.PublicURL != "" {
+		return strings.TrimSuffix(s.config.PublicURL, "/") + "/" + key, nil
+	}
+
+	signed, err := s.client.PresignedGetObject(ctx, s.config.Bucket, key, s.config.URLExpiry, url.Values{})
+	if err != nil {
+		return "", err
+	}
+	return signed.String(), nil
+}
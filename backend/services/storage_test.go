@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCleanKey(t *testing.T) {
+	tests := map[string]string{
+		"uploads/shoe.jpg":        "uploads/shoe.jpg",
+		"/uploads//shoe.jpg":      "uploads/shoe.jpg",
+		"../../etc/passwd":        "etc/passwd",
+		"uploads/../../shoe.jpg":  "shoe.jpg",
+		"uploads/./thumbs/a.webp": "uploads/thumbs/a.webp",
+	}
+	for key, want := range tests {
+		if got, err := cleanKey(key); err != nil || got != want {
+			t.Errorf("cleanKey(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	for _, key := range []string{"", "/", ".", ".."} {
+		if _, err := cleanKey(key); !errors.Is(err, ErrInvalidStorageKey) {
+			t.Errorf("cleanKey(%q) error = %v, want ErrInvalidStorageKey", key, err)
+		}
+	}
+}
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage := &LocalStorage{Dir: dir, BaseURL: "https://cdn.example.com/"}
+	data := []byte("image bytes")
+
+	if err := storage.Put(ctx, "uploads/shoe.jpg", data, "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "uploads", "shoe.jpg"))
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("stored file = %q, %v; want %q", got, err, data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "uploads", "shoe.jpg.tmp")); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+
+	url, err := storage.URL(ctx, "uploads/shoe.jpg")
+	if err != nil || url != "https://cdn.example.com/uploads/shoe.jpg" {
+		t.Errorf("URL = %q, %v", url, err)
+	}
+
+	if err := storage.Delete(ctx, "uploads/shoe.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "uploads", "shoe.jpg")); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Delete: %v", err)
+	}
+	if err := storage.Delete(ctx, "uploads/shoe.jpg"); err != nil {
+		t.Errorf("deleting a missing file: %v", err)
+	}
+}
+
+func TestLocalStorageStaysInDir(t *testing.T) {
+	dir := t.TempDir()
+	storage := &LocalStorage{Dir: filepath.Join(dir, "files")}
+
+	if err := storage.Put(context.Background(), "../escape.txt", []byte("x"), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.txt")); !os.IsNotExist(err) {
+		t.Error("file was written outside the storage directory")
+	}
+}
+
+// fakeS3 is an in-memory stand-in for an S3 bucket that understands the
+// requests S3Storage makes over plain HTTP.
+type fakeS3 struct {
+	bucket  string
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket != f.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if key == "" {
+		// HEAD bucket, used to check the bucket exists.
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = data
+		f.types[key] = r.Header.Get("Content-Type")
+		sum := md5.Sum(data)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// readS3Body returns an upload's bytes, decoding the aws-chunked encoding
+// used for signed uploads over plain HTTP.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, nil
+		}
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func TestS3StorageRoundTrip(t *testing.T) {
+	fake := &fakeS3{bucket: "media", objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	storage, err := NewS3Storage(S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Bucket:    "media",
+		AccessKey: "test",
+		SecretKey: "test-secret",
+		URLExpiry: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("image bytes "), 1000)
+	if err := storage.Put(ctx, "/uploads/shoe.jpg", data, "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := fake.objects["uploads/shoe.jpg"]; !bytes.Equal(got, data) {
+		t.Fatalf("stored %d bytes, want %d", len(got), len(data))
+	}
+	if got := fake.types["uploads/shoe.jpg"]; got != "image/jpeg" {
+		t.Errorf("content type = %q, want image/jpeg", got)
+	}
+
+	url, err := storage.URL(ctx, "uploads/shoe.jpg")
+	if err != nil {
+		t.Fatalf("URL: %v", err)
+	}
+	if !strings.Contains(url, "X-Amz-Signature=") {
+		t.Errorf("URL %q is not presigned", url)
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("fetching %s: %v", url, err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, data) {
+		t.Fatalf("GET %s = %d with %d bytes", url, resp.StatusCode, len(body))
+	}
+
+	if err := storage.Delete(ctx, "uploads/shoe.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := fake.objects["uploads/shoe.jpg"]; ok {
+		t.Error("object still exists after Delete")
+	}
+}
+
+func TestS3StoragePublicURL(t *testing.T) {
+	storage := &S3Storage{config: S3Config{PublicURL: "https://cdn.example.com/media/"}}
+
+	url, err := storage.URL(context.Background(), "uploads/shoe.jpg")
+	if err != nil || url != "https://cdn.example.com/media/uploads/shoe.jpg" {
+		t.Errorf("URL = %q, %v", url, err)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+var ErrFileTooLarge = errors.New("file is too large")
+
+// UploadLimits lists the content types accepted for uploads and the largest
+// file allowed for each. Types are sniffed from the file's bytes; the client's
+// filename and Content-Type header are never trusted.
+var UploadLimits = map[string]int64{
+	"image/jpeg": 10 << 20,
+	"image/png":  10 << 20,
+	"image/webp": 10 << 20,
+	"image/gif":  5 << 20,
+}
+
+// MaxUploadSize is the largest file any allowed type accepts, used to bound
+// how much of an upload is read before sniffing.
+func MaxUploadSize() int64 {
+	var max int64
+	for _, limit := range UploadLimits {
+		if limit > max {
+			max = limit
+		}
+	}
+	return max
+}
+
+// SniffUpload detects the content type of an upload and checks it against
+// UploadLimits. Errors wrap ErrUnsupportedFileType or ErrFileTooLarge with a
+// message that can be shown to the client.
+func SniffUpload(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	limit, ok := UploadLimits[contentType]
+	if !ok {
+		return "", fmt.Errorf("%w: %s (allowed: %s)", ErrUnsupportedFileType, contentType, allowedTypes())
+	}
+	if int64(len(data)) > limit {
+		return "", fmt.Errorf("%w: %s files must be %dMB or smaller", ErrFileTooLarge, contentType, limit>>20)
+	}
+	return contentType, nil
+}
+
+func allowedTypes() string {
+	types := make([]string, 0, len(UploadLimits))
+	for contentType := range UploadLimits {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	return strings.Join(types, ", ")
+}
+
+// ContentName derives a file name stem from an upload's bytes, so the same
+// file always lands at the same key and different files never collide.
+func ContentName(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
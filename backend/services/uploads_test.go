@@ -0,0 +1,46 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+var (
+	pngHeader  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	jpegHeader = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	gifHeader  = []byte("GIF89a\x01\x00\x01\x00")
+)
+
+func TestSniffUpload(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{pngHeader, "image/png"},
+		{jpegHeader, "image/jpeg"},
+		{gifHeader, "image/gif"},
+	}
+	for _, tt := range tests {
+		got, err := SniffUpload(tt.data)
+		if err != nil || got != tt.want {
+			t.Errorf("SniffUpload = %q, %v; want %q", got, err, tt.want)
+		}
+	}
+}
+
+func TestSniffUploadRejectsOtherTypes(t *testing.T) {
+	for _, data := range [][]byte{[]byte("<html><body>hi</body></html>"), []byte("%PDF-1.7\n"), []byte("plain text")} {
+		if _, err := SniffUpload(data); !errors.Is(err, ErrUnsupportedFileType) {
+			t.Errorf("SniffUpload(%q) error = %v, want ErrUnsupportedFileType", data, err)
+		}
+	}
+}
+
+func TestSniffUploadRejectsLargeFiles(t *testing.T) {
+	data := make([]byte, UploadLimits["image/gif"]+1)
+	copy(data, gifHeader)
+
+	if _, err := SniffUpload(data); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("error = %v, want ErrFileTooLarge", err)
+	}
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"ecommerce/backend/database"
+	"ecommerce/backend/models"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var WebhookEvents = []string{
+	EventOrderCreated,
+	EventOrderPaid,
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventProductRestored,
+	EventProductPublished,
+	EventProductUnpublished,
+	EventReviewRejected,
+}
+
+const (
+	webhookMaxAttempts  = 8
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = 6 * time.Hour
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 20
+	webhookSendTimeout  = 10 * time.Second
+	// webhookLease outlasts a batch of sends that all time out, so a claimed
+	// delivery is only picked up again if its worker died.
+	webhookLease = webhookBatchSize*webhookSendTimeout + time.Minute
+)
+
+var webhookClient = &http.Client{Timeout: webhookSendTimeout}
+
+func ValidWebhookEvent(event string) bool {
+	if event == "*" {
+		return true
+	}
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func NewWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// SignWebhook signs "<timestamp>.<body>" so receivers can reject replays.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type webhookEnvelope struct {
+	ID        uint            `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// HandleWebhookEvent is an outbox subscriber that records a pending delivery
+// for every active webhook subscribed to the event. Deliveries already
+// recorded for the event are skipped, since the outbox may replay it.
+func HandleWebhookEvent(event models.OutboxEvent) error {
+	var hooks []models.Webhook
+	if err := database.DB.Where("active = ?", true).Find(&hooks).Error; err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(webhookEnvelope{
+		ID:        event.ID,
+		Event:     event.Type,
+		CreatedAt: event.CreatedAt.UTC(),
+		Data:      json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, hook := range hooks {
+			if !hook.Subscribes(event.Type) {
+				continue
+			}
+
+			var existing int64
+			tx.Model(&models.WebhookDelivery{}).
+				Where("webhook_id = ? AND outbox_event_id = ?", hook.ID, event.ID).
+				Count(&existing)
+			if existing > 0 {
+				continue
+			}
+
+			delivery := models.WebhookDelivery{
+				WebhookID:     hook.ID,
+				OutboxEventID: &event.ID,
+				Event:         event.Type,
+				Payload:       string(payload),
+				Status:        models.DeliveryStatusPending,
+				NextAttemptAt: &now,
+			}
+			if err := tx.Create(&delivery).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Redeliver queues a fresh copy of an earlier delivery, keeping the original
+// in the log.
+func Redeliver(original models.WebhookDelivery) (models.WebhookDelivery, error) {
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		WebhookID:     original.WebhookID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.DeliveryStatusPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}
+	err := database.DB.Create(&delivery).Error
+	return delivery, err
+}
+
+// StartWebhookWorker polls for due deliveries until the process exits.
+func StartWebhookWorker() {
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := processWebhookDeliveries(); err != nil {
+				log.Printf("Webhook worker error: %v", err)
+			}
+		}
+	}()
+}
+
+// processWebhookDeliveries sends due deliveries. They are claimed in a short
+// transaction and sent outside it, so no row locks are held while receivers
+// respond, and each result is recorded as soon as it is known.
+func processWebhookDeliveries() error {
+	deliveries, err := claimWebhookDeliveries()
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		var hook models.Webhook
+		if err := database.DB.First(&hook, delivery.WebhookID).Error; err != nil {
+			delivery.Status = models.DeliveryStatusFailed
+			delivery.LastError = "webhook no longer exists"
+			delivery.NextAttemptAt = nil
+		} else {
+			attemptDelivery(hook, delivery)
+		}
+
+		if err := database.DB.Save(delivery).Error; err != nil {
+			log.Printf("Webhook delivery %d: failed to record attempt: %v", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// claimWebhookDeliveries leases due deliveries to this worker by moving
+// their next attempt past the lease.
+func claimWebhookDeliveries() ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED lets several instances share the queue.
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(webhookBatchSize).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			UpdateColumn("next_attempt_at", time.Now().Add(webhookLease)).Error
+	})
+	return deliveries, err
+}
+
+func attemptDelivery(hook models.Webhook, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+
+	statusCode, err := sendWebhook(hook, delivery)
+	delivery.LastStatusCode = statusCode
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.DeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+
+	next := time.Now().Add(webhookBackoff(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+// webhookBackoff doubles the wait after every failed attempt.
+func webhookBackoff(attempts int) time.Duration {
+	wait := webhookBaseBackoff << uint(attempts-1)
+	if wait <= 0 || wait > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return wait
+}
+
+func sendWebhook(hook models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ecommerce-webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", SignWebhook(hook.Secret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"crypto/rand"
+	"ecommerce/backend/models"
+	"encoding/base64"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const DefaultWishlistName = "Wishlist"
+
+// DefaultWishlist returns the user's default wishlist, creating it the first
+// time it is needed. A unique index allows one default list per user, so
+// concurrent first requests end up with the same list.
+func DefaultWishlist(tx *gorm.DB, userID uint) (models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := tx.Where("user_id = ? AND is_default", userID).First(&wishlist).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return wishlist, err
+	}
+
+	wishlist = models.Wishlist{UserID: userID, Name: DefaultWishlistName, IsDefault: true}
+	err = tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "user_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "is_default"}}},
+		DoNothing:   true,
+	}).Create(&wishlist).Error
+	if err != nil {
+		return wishlist, err
+	}
+
+	err = tx.Where("user_id = ? AND is_default", userID).First(&wishlist).Error
+	return wishlist, err
+}
+
+// NewShareToken returns an unguessable token for a wishlist's public link.
+func NewShareToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
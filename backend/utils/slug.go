@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Slugify lowercases s and joins its letters and digits with hyphens, so
+// "Smartphones & Tablets" becomes "smartphones-tablets".
+func Slugify(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pendingHyphen = false
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
+
+const maxSlugLength = 80
+
+// ResourceSlug slugifies s for a URL that also accepts numeric IDs. Long
+// slugs are cut to a readable length, a slug made only of digits gets
+// fallback as a prefix so it cannot be mistaken for an ID, and fallback
+// stands in for an empty one.
+func ResourceSlug(s, fallback string) string {
+	slug := Slugify(s)
+	if runes := []rune(slug); len(runes) > maxSlugLength {
+		slug = strings.TrimRight(string(runes[:maxSlugLength]), "-")
+	}
+
+	switch {
+	case slug == "":
+		return fallback
+	case strings.Trim(slug, "0123456789") == "":
+		return fallback + "-" + slug
+	}
+	return slug
+}
+
+// UniqueSlug returns base, or base numbered from 2 up, whichever is the first
+// that taken reports as free.
+func UniqueSlug(base string, taken func(string) bool) string {
+	slug := base
+	for n := 2; taken(slug); n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	return slug
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Smartphones & Tablets", "smartphones-tablets"},
+		{"  Leading and trailing  ", "leading-and-trailing"},
+		{"Already-a-slug", "already-a-slug"},
+		{"Crème Brûlée", "crème-brûlée"},
+		{"4K   TVs!!", "4k-tvs"},
+		{"---", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.in); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResourceSlug(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Running Shoes", "running-shoes"},
+		{"", "product"},
+		{"!!!", "product"},
+		{"2024", "product-2024"},
+		{"12 34", "12-34"},
+		{"Model 3", "model-3"},
+	}
+	for _, tt := range tests {
+		if got := ResourceSlug(tt.in, "product"); got != tt.want {
+			t.Errorf("ResourceSlug(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResourceSlugTruncates(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	got := ResourceSlug(long, "product")
+
+	if n := len([]rune(got)); n > maxSlugLength {
+		t.Errorf("slug has %d runes, want at most %d", n, maxSlugLength)
+	}
+	if strings.HasSuffix(got, "-") {
+		t.Errorf("slug %q ends with a hyphen", got)
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	taken := map[string]bool{"shoe": true, "shoe-2": true}
+	if got := UniqueSlug("shoe", func(s string) bool { return taken[s] }); got != "shoe-3" {
+		t.Errorf("UniqueSlug = %q, want shoe-3", got)
+	}
+	if got := UniqueSlug("boot", func(s string) bool { return taken[s] }); got != "boot" {
+		t.Errorf("UniqueSlug = %q, want boot", got)
+	}
+}
@@ -12,7 +12,7 @@ export async function GET() {
       throw new Error(`Failed to fetch products: ${res.status}`);
     }
 
-    const data = await res.json();
+    const { data } = await res.json();
     return Response.json(data);
   } catch (error) {
     return Response.json({ error: error.message }, { status: 500 });
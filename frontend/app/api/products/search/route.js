@@ -1,4 +1,5 @@
 import { NextResponse } from 'next/server';
+import { toMinorUnits } from '@/utils/price';
 
 const API_URL = process.env.API_URL || 'http://localhost:8080';
 
@@ -13,10 +14,11 @@ export async function GET(request) {
     const params = new URLSearchParams();
     if (query) params.append('q', query);
     if (category) params.append('category', category);
-    if (minPrice) params.append('minPrice', minPrice);
-    if (maxPrice) params.append('maxPrice', maxPrice);
+    if (minPrice) params.append('min_price', toMinorUnits(minPrice));
+    if (maxPrice) params.append('max_price', toMinorUnits(maxPrice));
 
-    const url = `${API_URL}/products?${params.toString()}`;
+    const path = query ? '/products/search' : '/products';
+    const url = `${API_URL}${path}?${params.toString()}`;
     
     const res = await fetch(url, {
       headers: {
@@ -28,7 +30,7 @@ export async function GET(request) {
       throw new Error(`Failed to fetch products: ${res.status}`);
     }
 
-    const data = await res.json();
+    const { data } = await res.json();
     return NextResponse.json(data);
   } catch (error) {
     return NextResponse.json({ error: error.message }, { status: 500 });
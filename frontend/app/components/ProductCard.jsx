@@ -2,6 +2,7 @@
 
 import { useState } from "react";
 import Link from "next/link";
+import { formatPrice } from "@/utils/price";
 
 export default function ProductCard({ product }) {
   const [backendUrl, setBackendUrl] = useState("");
@@ -59,7 +60,7 @@ export default function ProductCard({ product }) {
 
           <div>
             <span className="text-2xl font-bold text-gray-900 dark:text-white">
-              ${product.price}
+              {formatPrice(product.price, product.currency)}
             </span>
           </div>
         <div className="flex items-center justify-between mt-4">
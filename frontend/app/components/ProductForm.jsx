@@ -2,11 +2,13 @@
 
 import { stringify } from "querystring";
 import { useState, useEffect } from "react";
+import { toMajorUnits, toMinorUnits } from "@/utils/price";
 
 export default function ProductForm({ id }) {
   const [form, setForm] = useState({
     title: "",
     price: "",
+    currency: "",
     description: "",
     category: "",
     imageFile: null,
@@ -27,7 +29,8 @@ export default function ProductForm({ id }) {
         const data = await res.json();
         setForm({
           title: data.title || "",
-          price: data.price || "",
+          price: data.price ? toMajorUnits(data.price, data.currency) : "",
+          currency: data.currency || "",
           description: data.description || "",
           category: data.category || "",
           imageFile: null,
@@ -77,7 +80,7 @@ const handleSubmit = async (e) => {
     if (form.imageFile) {
       const formData = new FormData();
       formData.append("title", form.title);
-      formData.append("price", form.price);
+      formData.append("price", toMinorUnits(form.price, form.currency));
       formData.append("description", form.description);
       formData.append("category", form.category);
       formData.append("image", form.imageFile);
@@ -130,7 +133,7 @@ const handleSubmit = async (e) => {
         },
         body: JSON.stringify({
           title: form.title,
-          price: form.price,
+          price: toMinorUnits(form.price, form.currency),
           description: form.description,
           category: form.category,
         }),
@@ -220,7 +223,7 @@ const handleSubmit = async (e) => {
               <input
                 name="price"
                 type="number"
-                step="1"
+                step="0.01"
                 min="0"
                 value={form.price}
                 onChange={handleChange}
@@ -2,6 +2,7 @@
 
 import Link from "next/link";
 import { useEffect, useState } from "react";
+import { formatPrice } from "@/utils/price";
 
 export default function ProductTable({ products }) {
   const handleDelete = async (id) => {
@@ -110,7 +111,7 @@ export default function ProductTable({ products }) {
               </td>
               <td className="px-6 py-4 whitespace-nowrap">
                 <div className="text-sm font-semibold text-gray-900 dark:text-gray-100">
-                  ${p.price}
+                  {formatPrice(p.price, p.currency)}
                 </div>
               </td>
               <td className="px-6 py-4 whitespace-nowrap text-sm">
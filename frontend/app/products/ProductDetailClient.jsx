@@ -4,6 +4,7 @@ import { useState, useEffect } from "react";
 import { useRouter } from "next/navigation";
 import Link from "next/link";
 import Image from "next/image";
+import { formatPrice, toMajorUnits, toMinorUnits } from "@/utils/price";
 
 const getUser = () => {
   if (typeof window === 'undefined') return null;
@@ -57,7 +58,7 @@ export default function ProductDetailClient({ initialProduct }) {
     if (product) {
       setEditForm({
         title: product.title || "",
-        price: product.price || "",
+        price: product.price ? toMajorUnits(product.price, product.currency) : "",
         description: product.description || "",
         category: product.category || "",
       });
@@ -99,7 +100,7 @@ export default function ProductDetailClient({ initialProduct }) {
     setIsEditing(false);
     setEditForm({
       title: product.title || "",
-      price: product.price || "",
+      price: product.price ? toMajorUnits(product.price, product.currency) : "",
       description: product.description || "",
       category: product.category || "",
     });
@@ -110,7 +111,7 @@ export default function ProductDetailClient({ initialProduct }) {
     const { name, value } = e.target;
     setEditForm((prev) => ({
       ...prev,
-      [name]: name === "price" ? parseInt(value) || "" : value,
+      [name]: value,
     }));
   };
 
@@ -134,7 +135,10 @@ export default function ProductDetailClient({ initialProduct }) {
         "Content-Type": "application/json",
         ...(token && { 'Authorization': `Bearer ${token}` })
       },
-      body: JSON.stringify(editForm),
+      body: JSON.stringify({
+        ...editForm,
+        price: toMinorUnits(editForm.price, product.currency),
+      }),
     });
 
     if (!res.ok) {
@@ -550,7 +554,7 @@ const handleDelete = async () => {
                         {product.title}
                       </h1>
                       <span className="text-3xl font-bold text-blue-600 dark:text-blue-400">
-                        ${product.price}
+                        {formatPrice(product.price, product.currency)}
                       </span>
                     </div>
 
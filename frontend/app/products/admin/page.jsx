@@ -2,7 +2,7 @@ import ProductTable from "@/app/components/ProductTable";
 import Link from "next/link";
 
 export default async function ProductsPage() {
-  const products = await fetch(process.env.VITE_API_URL + "/products").then((r) =>
+  const { data: products } = await fetch(process.env.VITE_API_URL + "/products").then((r) =>
     r.json()
   );
 
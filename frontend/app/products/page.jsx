@@ -4,6 +4,7 @@ import { useState, useEffect } from "react";
 import ProductCard from "@/app/components/ProductCard";
 import SearchFilters from "@/app/components/SearchFilter";
 import LoadingSpinner from "@/app/components/LoadingSpinner";
+import { toMinorUnits } from "@/utils/price";
 
 export default function ProductsPage() {
   const [products, setProducts] = useState([]);
@@ -87,10 +88,10 @@ export default function ProductsPage() {
           product.category === selectedCategory;
         
         const matchesMinPrice = !priceRange.min || 
-          product.price >= parseInt(priceRange.min);
+          product.price >= toMinorUnits(priceRange.min, product.currency);
         
         const matchesMaxPrice = !priceRange.max || 
-          product.price <= parseInt(priceRange.max);
+          product.price <= toMinorUnits(priceRange.max, product.currency);
         
         return matchesSearch && matchesCategory && matchesMinPrice && matchesMaxPrice;
       });
@@ -0,0 +1,29 @@
+// The API sends prices in minor units (cents for USD) together with their
+// currency. These helpers convert to and from what people read and type.
+
+const DEFAULT_CURRENCY = "USD";
+
+function formatter(currency) {
+  return new Intl.NumberFormat("en-US", {
+    style: "currency",
+    currency: currency || DEFAULT_CURRENCY,
+  });
+}
+
+function scale(currency) {
+  return 10 ** formatter(currency).resolvedOptions().maximumFractionDigits;
+}
+
+export function formatPrice(amount, currency) {
+  return formatter(currency).format(toMajorUnits(amount, currency));
+}
+
+export function toMajorUnits(amount, currency) {
+  return Number(amount || 0) / scale(currency);
+}
+
+export function toMinorUnits(value, currency) {
+  const number = parseFloat(value);
+  if (Number.isNaN(number)) return 0;
+  return Math.round(number * scale(currency));
+}